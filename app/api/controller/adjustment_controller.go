@@ -0,0 +1,118 @@
+package controller
+
+import "time"
+
+// Причины корректировки остатка
+const (
+	AdjustmentDamaged = "damaged"
+	AdjustmentLost    = "lost"
+	AdjustmentFound   = "found"
+	AdjustmentRecount = "recount"
+)
+
+var (
+	// ErrInvalidReason возвращается для неизвестной причины корректировки
+	ErrInvalidReason = &Error{Kind: KindValidation, Code: "invalid_reason", Message: "unknown adjustment reason"}
+	// ErrActorRequired возвращается, если не указано, кто выполняет корректировку
+	ErrActorRequired = &Error{Kind: KindValidation, Code: "actor_required", Message: "empty adjustment actor"}
+	// ErrAdjustmentTarget возвращается, если не указано ровно одно из quantity и delta
+	ErrAdjustmentTarget = &Error{Kind: KindValidation, Code: "adjustment_target", Message: "exactly one of quantity and delta must be set"}
+)
+
+// adjustmentReasons допустимые причины корректировки
+var adjustmentReasons = map[string]bool{
+	AdjustmentDamaged: true,
+	AdjustmentLost:    true,
+	AdjustmentFound:   true,
+	AdjustmentRecount: true,
+}
+
+// Adjustment корректировка остатка продукта.
+// Quantity задает пересчитанный остаток, Delta - изменение со знаком, указывается ровно одно из них.
+// Actor и Note фиксируют, кто и почему изменил остаток
+type Adjustment struct {
+	ID             int       `json:"id"`
+	ProductID      int       `json:"product_id"`
+	WarehouseID    int       `json:"warehouse_id"`
+	Code           string    `json:"code"`
+	Reason         string    `json:"reason"`
+	Quantity       *int      `json:"quantity,omitempty"`
+	Delta          *int      `json:"delta,omitempty"`
+	Actor          string    `json:"actor"`
+	Note           string    `json:"note"`
+	QuantityBefore int       `json:"quantity_before"`
+	QuantityAfter  int       `json:"quantity_after"`
+	CreatedAt      time.Time `json:"created_at"`
+}
+
+//	@Summary		Adjust product stock
+//	@Description	Sets a product's quantity to a counted value or applies a signed delta with a reason (damaged, lost, found, recount).
+//	@Description	Quantity cannot become negative or drop below reserved units.
+//	@Tags			adjustments
+//	@Accept			json
+//	@Produce		json
+//	@Param			adjustment	body		Adjustment	true	"Product, reason, actor and quantity or delta"
+//	@Success		201			{object}	Adjustment
+//	@Failure		400			{object}	route.ErrorResponse	"Invalid request format"
+//	@Failure		404			{object}	route.ErrorResponse	"Product not found"
+//	@Failure		409			{object}	route.ErrorResponse	"Quantity is below reserved quantity"
+//	@Failure		422			{object}	route.ErrorResponse	"Invalid reason, actor or quantity"
+//	@Failure		500			{object}	route.ErrorResponse	"Internal server error"
+//	@Router			/adjustments [post]
+//
+// AdjustProduct корректирует остаток продукта и записывает корректировку
+func AdjustProduct(s Store, a *Adjustment) error {
+	if !adjustmentReasons[a.Reason] {
+		return ErrInvalidReason
+	}
+	if a.Actor == "" {
+		return ErrActorRequired
+	}
+	if (a.Quantity == nil) == (a.Delta == nil) {
+		return ErrAdjustmentTarget
+	}
+
+	return s.InTx(func(tx Repositories) error {
+		// Блокируем продукт, чтобы резерв не изменился до проверки остатка
+		p, err := tx.Products().GetForUpdate(a.ProductID)
+		if err != nil {
+			return err
+		}
+
+		after := p.Quantity
+		if a.Quantity != nil {
+			after = *a.Quantity
+		} else {
+			after += *a.Delta
+		}
+		if after < 0 {
+			return ErrNegativeQuantity.WithProduct(p.Code)
+		}
+		// Зарезервированные единицы должны остаться на складе
+		if after < p.ReservedQuantity {
+			return ErrQuantityBelowReserved.WithProduct(p.Code)
+		}
+
+		a.WarehouseID = p.WarehouseID
+		a.Code = p.Code
+		a.QuantityBefore = p.Quantity
+		a.QuantityAfter = after
+		delta := after - p.Quantity
+		a.Quantity = &after
+		a.Delta = &delta
+
+		if err := tx.Adjustments().Create(a); err != nil {
+			return err
+		}
+
+		// Пересчет без расхождений тоже сохраняется, но остаток и журнал не меняет
+		if delta == 0 {
+			return nil
+		}
+		if err := tx.Products().AddStock(p.ID, delta, 0); err != nil {
+			return err
+		}
+
+		return tx.Movements().Record(StockMovement{ProductID: p.ID, WarehouseID: p.WarehouseID, Code: p.Code, Kind: MovementAdjust, QuantityDelta: delta, AdjustmentID: &a.ID})
+	})
+}
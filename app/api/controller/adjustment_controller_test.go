@@ -0,0 +1,73 @@
+package controller_test
+
+import (
+	"errors"
+	"testing"
+
+	"lamoda-test/api/controller"
+	"lamoda-test/utils"
+)
+
+func TestAdjustProduct(t *testing.T) {
+	store := newStore(t)
+
+	// Создаем склад и продукт, резервируем две единицы
+	w := &controller.Warehouse{Name: utils.RandomString(6), IsAvailable: true}
+	err := controller.CreateWarehouse(store, w)
+	if err != nil {
+		t.Fatal(err)
+	}
+	p := &controller.Product{
+		Name:        utils.RandomString(6),
+		Size:        utils.RandomSize(),
+		Code:        utils.RandomString(6),
+		Quantity:    10,
+		WarehouseID: w.ID,
+	}
+	err = controller.CreateProduct(store, p)
+	if err != nil {
+		t.Fatal(err)
+	}
+	err = controller.ReserveProducts(store, &controller.Reservation{OrderRef: utils.RandomString(6), Lines: []controller.ReservationLine{{Code: p.Code, Quantity: 2}}})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// Списываем три поврежденные единицы
+	delta := -3
+	a := &controller.Adjustment{ProductID: p.ID, Reason: controller.AdjustmentDamaged, Delta: &delta, Actor: utils.RandomString(6)}
+	err = controller.AdjustProduct(store, a)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if a.QuantityBefore != 10 || a.QuantityAfter != 7 {
+		t.Errorf("Expected quantity 10 -> 7, got %d -> %d", a.QuantityBefore, a.QuantityAfter)
+	}
+
+	// Пересчет задает остаток напрямую
+	counted := 5
+	err = controller.AdjustProduct(store, &controller.Adjustment{ProductID: p.ID, Reason: controller.AdjustmentRecount, Quantity: &counted, Actor: utils.RandomString(6)})
+	if err != nil {
+		t.Fatal(err)
+	}
+	got, err := controller.GetProduct(store, p.ID)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got.Quantity != 5 {
+		t.Errorf("Expected quantity 5 after recount, got %d", got.Quantity)
+	}
+
+	// Остаток не может опуститься ниже резерва
+	counted = 1
+	err = controller.AdjustProduct(store, &controller.Adjustment{ProductID: p.ID, Reason: controller.AdjustmentLost, Quantity: &counted, Actor: utils.RandomString(6)})
+	if !errors.Is(err, controller.ErrQuantityBelowReserved) {
+		t.Errorf("Expected ErrQuantityBelowReserved, got %v", err)
+	}
+
+	// Причина обязательна
+	err = controller.AdjustProduct(store, &controller.Adjustment{ProductID: p.ID, Reason: "stolen", Delta: &delta, Actor: utils.RandomString(6)})
+	if !errors.Is(err, controller.ErrInvalidReason) {
+		t.Errorf("Expected ErrInvalidReason, got %v", err)
+	}
+}
@@ -0,0 +1,148 @@
+package controller
+
+import (
+	"math"
+	"sort"
+)
+
+// Названия стратегий распределения резерва по складам
+const (
+	StrategyFewestSplits = "fewest_splits"
+	StrategyPriority     = "priority"
+	StrategyNearest      = "nearest"
+)
+
+var (
+	// ErrUnknownStrategy возвращается для неизвестного названия стратегии
+	ErrUnknownStrategy = &Error{Kind: KindValidation, Code: "unknown_strategy", Message: "unknown allocation strategy"}
+	// ErrLocationRequired возвращается, если для стратегии nearest не передано местоположение
+	ErrLocationRequired = &Error{Kind: KindValidation, Code: "location_required", Message: "location is required for nearest strategy"}
+)
+
+// Location географические координаты
+type Location struct {
+	Latitude  float64 `json:"latitude"`
+	Longitude float64 `json:"longitude"`
+}
+
+// StockLevel доступный для резервирования остаток продукта на одном складе
+type StockLevel struct {
+	ProductID int
+	Warehouse Warehouse
+	Available int
+}
+
+// Allocation сколько единиц продукта взять с конкретного склада
+type Allocation struct {
+	ProductID   int
+	WarehouseID int
+	Quantity    int
+}
+
+// AllocationStrategy распределяет запрошенное количество по складам.
+// Allocate возвращает nil, если остатков на всех складах не хватает
+type AllocationStrategy interface {
+	Allocate(stock []StockLevel, quantity int) []Allocation
+}
+
+// NewAllocationStrategy возвращает стратегию по названию, пустое название - FewestSplits
+func NewAllocationStrategy(name string, location *Location) (AllocationStrategy, error) {
+	switch name {
+	case "", StrategyFewestSplits:
+		return FewestSplits{}, nil
+	case StrategyPriority:
+		return PriorityOrder{}, nil
+	case StrategyNearest:
+		if location == nil {
+			return nil, ErrLocationRequired
+		}
+		return Nearest{Location: *location}, nil
+	}
+
+	return nil, ErrUnknownStrategy
+}
+
+// FewestSplits берет остатки с наибольших складов, чтобы заказ собирался с наименьшего числа складов
+type FewestSplits struct{}
+
+func (FewestSplits) Allocate(stock []StockLevel, quantity int) []Allocation {
+	return allocateInOrder(stock, quantity, func(a, b StockLevel) bool {
+		return a.Available > b.Available
+	})
+}
+
+// PriorityOrder берет остатки в порядке приоритета складов, меньшее значение - раньше
+type PriorityOrder struct{}
+
+func (PriorityOrder) Allocate(stock []StockLevel, quantity int) []Allocation {
+	return allocateInOrder(stock, quantity, func(a, b StockLevel) bool {
+		return a.Warehouse.Priority < b.Warehouse.Priority
+	})
+}
+
+// Nearest берет остатки с ближайших к Location складов, склады без координат - в последнюю очередь
+type Nearest struct {
+	Location Location
+}
+
+func (n Nearest) Allocate(stock []StockLevel, quantity int) []Allocation {
+	return allocateInOrder(stock, quantity, func(a, b StockLevel) bool {
+		return n.distance(a.Warehouse) < n.distance(b.Warehouse)
+	})
+}
+
+// distance возвращает расстояние до склада в километрах по формуле гаверсинусов
+func (n Nearest) distance(w Warehouse) float64 {
+	if w.Latitude == nil || w.Longitude == nil {
+		return math.Inf(1)
+	}
+
+	const earthRadius = 6371.0
+	rad := func(deg float64) float64 { return deg * math.Pi / 180 }
+
+	dLat := rad(*w.Latitude - n.Location.Latitude)
+	dLon := rad(*w.Longitude - n.Location.Longitude)
+	h := math.Sin(dLat/2)*math.Sin(dLat/2) +
+		math.Cos(rad(n.Location.Latitude))*math.Cos(rad(*w.Latitude))*math.Sin(dLon/2)*math.Sin(dLon/2)
+
+	return 2 * earthRadius * math.Asin(math.Sqrt(h))
+}
+
+// allocateInOrder сортирует склады по less и набирает количество жадно.
+// При равенстве склады берутся в порядке ID, чтобы распределение было детерминированным
+func allocateInOrder(stock []StockLevel, quantity int, less func(a, b StockLevel) bool) []Allocation {
+	sorted := make([]StockLevel, len(stock))
+	copy(sorted, stock)
+	sort.SliceStable(sorted, func(i, j int) bool {
+		if less(sorted[i], sorted[j]) {
+			return true
+		}
+		if less(sorted[j], sorted[i]) {
+			return false
+		}
+		return sorted[i].Warehouse.ID < sorted[j].Warehouse.ID
+	})
+
+	var allocations []Allocation
+	for _, s := range sorted {
+		if quantity == 0 {
+			break
+		}
+		if s.Available <= 0 {
+			continue
+		}
+
+		take := s.Available
+		if take > quantity {
+			take = quantity
+		}
+		allocations = append(allocations, Allocation{ProductID: s.ProductID, WarehouseID: s.Warehouse.ID, Quantity: take})
+		quantity -= take
+	}
+
+	if quantity > 0 {
+		return nil
+	}
+
+	return allocations
+}
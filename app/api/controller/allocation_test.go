@@ -0,0 +1,74 @@
+package controller
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestAllocationStrategies(t *testing.T) {
+	lat := func(v float64) *float64 { return &v }
+
+	// Склад 1 - крупный, но дальний и с низким приоритетом, склад 3 - без координат
+	stock := []StockLevel{
+		{ProductID: 11, Warehouse: Warehouse{ID: 1, Priority: 2, Latitude: lat(59.93), Longitude: lat(30.31)}, Available: 5},
+		{ProductID: 12, Warehouse: Warehouse{ID: 2, Priority: 1, Latitude: lat(55.75), Longitude: lat(37.61)}, Available: 2},
+		{ProductID: 13, Warehouse: Warehouse{ID: 3, Priority: 0}, Available: 3},
+	}
+
+	tests := []struct {
+		name     string
+		strategy AllocationStrategy
+		quantity int
+		want     []Allocation
+	}{
+		{
+			name:     "fewest splits takes the largest warehouse first",
+			strategy: FewestSplits{},
+			quantity: 6,
+			want:     []Allocation{{ProductID: 11, WarehouseID: 1, Quantity: 5}, {ProductID: 13, WarehouseID: 3, Quantity: 1}},
+		},
+		{
+			name:     "priority order takes the lowest priority value first",
+			strategy: PriorityOrder{},
+			quantity: 4,
+			want:     []Allocation{{ProductID: 13, WarehouseID: 3, Quantity: 3}, {ProductID: 12, WarehouseID: 2, Quantity: 1}},
+		},
+		{
+			name:     "nearest takes the closest warehouse first and unknown locations last",
+			strategy: Nearest{Location: Location{Latitude: 55.76, Longitude: 37.62}},
+			quantity: 8,
+			want:     []Allocation{{ProductID: 12, WarehouseID: 2, Quantity: 2}, {ProductID: 11, WarehouseID: 1, Quantity: 5}, {ProductID: 13, WarehouseID: 3, Quantity: 1}},
+		},
+		{
+			name:     "not enough stock",
+			strategy: FewestSplits{},
+			quantity: 11,
+			want:     nil,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := tt.strategy.Allocate(stock, tt.quantity)
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("Expected %+v, got %+v", tt.want, got)
+			}
+		})
+	}
+}
+
+func TestNewAllocationStrategy(t *testing.T) {
+	if _, err := NewAllocationStrategy("unknown", nil); err != ErrUnknownStrategy {
+		t.Errorf("Expected ErrUnknownStrategy, got %v", err)
+	}
+	if _, err := NewAllocationStrategy(StrategyNearest, nil); err != ErrLocationRequired {
+		t.Errorf("Expected ErrLocationRequired, got %v", err)
+	}
+	s, err := NewAllocationStrategy("", nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, ok := s.(FewestSplits); !ok {
+		t.Errorf("Expected FewestSplits by default, got %T", s)
+	}
+}
@@ -0,0 +1,63 @@
+package controller
+
+import "errors"
+
+// ErrorKind вид ошибки предметной области, по нему выбирается HTTP статус
+type ErrorKind int
+
+const (
+	KindInternal ErrorKind = iota
+	KindNotFound
+	KindOutOfStock
+	KindConflict
+	KindValidation
+)
+
+// Error ошибка предметной области со стабильным машиночитаемым кодом.
+// ProductCode и WarehouseID указывают на продукт или склад, из-за которого операция не выполнена,
+// Fields - на поля запроса, не прошедшие проверку
+type Error struct {
+	Kind        ErrorKind
+	Code        string
+	Message     string
+	ProductCode string
+	WarehouseID int
+	Fields      []FieldError
+}
+
+func (e *Error) Error() string {
+	return e.Message
+}
+
+// Is сравнивает ошибки по коду, чтобы errors.Is находил sentinel-ошибку, уточненную продуктом или складом
+func (e *Error) Is(target error) bool {
+	t, ok := target.(*Error)
+	return ok && t.Code == e.Code
+}
+
+// WithProduct возвращает копию ошибки с кодом продукта
+func (e *Error) WithProduct(code string) *Error {
+	c := *e
+	c.ProductCode = code
+	return &c
+}
+
+// WithWarehouse возвращает копию ошибки с ID склада
+func (e *Error) WithWarehouse(id int) *Error {
+	c := *e
+	c.WarehouseID = id
+	return &c
+}
+
+// ErrInternal описание непредвиденной ошибки, детали которой не показываются клиенту
+var ErrInternal = &Error{Kind: KindInternal, Code: "internal", Message: "internal server error"}
+
+// AsError приводит ошибку к ошибке предметной области, непредвиденные ошибки становятся ErrInternal
+func AsError(err error) *Error {
+	var e *Error
+	if errors.As(err, &e) {
+		return e
+	}
+
+	return ErrInternal
+}
@@ -0,0 +1,52 @@
+package controller
+
+import (
+	"database/sql"
+	"errors"
+	"fmt"
+	"testing"
+)
+
+func TestErrorIsMatchesByCode(t *testing.T) {
+	// Уточненная продуктом ошибка остается той же sentinel-ошибкой
+	err := fmt.Errorf("reserve: %w", ErrOutOfStock.WithProduct("ABC"))
+	if !errors.Is(err, ErrOutOfStock) {
+		t.Errorf("Expected %v to match ErrOutOfStock", err)
+	}
+	if errors.Is(err, ErrProductNotFound) {
+		t.Errorf("Expected %v not to match ErrProductNotFound", err)
+	}
+	if err.Error() != "reserve: product is out of stock" {
+		t.Errorf("Expected message to be unchanged, got %q", err.Error())
+	}
+}
+
+func TestAsError(t *testing.T) {
+	tests := []struct {
+		name        string
+		err         error
+		wantKind    ErrorKind
+		wantCode    string
+		wantProduct string
+		wantID      int
+	}{
+		{"domain", ErrOutOfStock.WithProduct("ABC"), KindOutOfStock, "out_of_stock", "ABC", 0},
+		{"wrapped", fmt.Errorf("wrap: %w", ErrWarehouseNotFound.WithWarehouse(7)), KindNotFound, "warehouse_not_found", "", 7},
+		{"unavailable", ErrWarehouseUnavailable.WithWarehouse(3), KindConflict, "warehouse_unavailable", "", 3},
+		{"internal", sql.ErrConnDone, KindInternal, "internal", "", 0},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			e := AsError(tt.err)
+			if e.Kind != tt.wantKind || e.Code != tt.wantCode || e.ProductCode != tt.wantProduct || e.WarehouseID != tt.wantID {
+				t.Errorf("Expected %v/%s/%q/%d, got %+v", tt.wantKind, tt.wantCode, tt.wantProduct, tt.wantID, e)
+			}
+		})
+	}
+
+	// Текст непредвиденной ошибки не должен попадать в ответ
+	if msg := AsError(sql.ErrConnDone).Message; msg == sql.ErrConnDone.Error() {
+		t.Errorf("Expected internal error details to be hidden, got %q", msg)
+	}
+}
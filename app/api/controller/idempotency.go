@@ -0,0 +1,64 @@
+package controller
+
+import "time"
+
+// idempotencyLockTimeout время, после которого незавершенный запрос считается брошенным и ключ освобождается
+const idempotencyLockTimeout = time.Minute
+
+var (
+	// ErrIdempotencyKeyReused возвращается, если ключ уже использован с другим телом запроса
+	ErrIdempotencyKeyReused = &Error{Kind: KindValidation, Code: "idempotency_key_reused", Message: "idempotency key was used with a different request"}
+	// ErrIdempotencyKeyInProgress возвращается, если запрос с этим ключом еще выполняется
+	ErrIdempotencyKeyInProgress = &Error{Kind: KindConflict, Code: "idempotency_key_in_progress", Message: "request with this idempotency key is in progress"}
+)
+
+// IdempotentRequest запрос с ключом идемпотентности и сохраненный ответ на него.
+// Ключ действует в пределах метода и пути, RequestHash защищает от повторного использования ключа с другим телом.
+// Status равен 0, пока первый запрос с ключом выполняется
+type IdempotentRequest struct {
+	Key         string
+	Method      string
+	Path        string
+	RequestHash string
+	Status      int
+	ContentType string
+	Body        []byte
+	CreatedAt   time.Time
+}
+
+// ClaimIdempotencyKey закрепляет ключ за запросом.
+// Возвращает nil, если ключ новый и запрос нужно выполнить, иначе сохраненный ответ для повтора.
+// Ключи старше ttl считаются свободными
+func ClaimIdempotencyKey(s Store, req *IdempotentRequest, ttl time.Duration) (*IdempotentRequest, error) {
+	stored, err := s.Idempotency().Claim(req, ttl, idempotencyLockTimeout)
+	if err != nil {
+		return nil, err
+	}
+	if stored == nil {
+		return nil, nil
+	}
+
+	if stored.RequestHash != req.RequestHash {
+		return nil, ErrIdempotencyKeyReused
+	}
+	if stored.Status == 0 {
+		return nil, ErrIdempotencyKeyInProgress
+	}
+
+	return stored, nil
+}
+
+// SaveIdempotentResponse сохраняет ответ на запрос, закрепленный ClaimIdempotencyKey
+func SaveIdempotentResponse(s Store, req *IdempotentRequest) error {
+	return s.Idempotency().Save(req)
+}
+
+// ReleaseIdempotencyKey освобождает ключ незавершенного запроса, чтобы его можно было повторить
+func ReleaseIdempotencyKey(s Store, req *IdempotentRequest) error {
+	return s.Idempotency().Release(req)
+}
+
+// PurgeIdempotencyKeys удаляет ключи старше ttl и возвращает их количество
+func PurgeIdempotencyKeys(s Store, ttl time.Duration) (int, error) {
+	return s.Idempotency().Purge(ttl)
+}
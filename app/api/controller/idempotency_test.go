@@ -0,0 +1,51 @@
+package controller_test
+
+import (
+	"errors"
+	"net/http"
+	"testing"
+	"time"
+
+	"lamoda-test/api/controller"
+	"lamoda-test/utils"
+)
+
+func TestClaimIdempotencyKey(t *testing.T) {
+	store := newStore(t)
+
+	req := &controller.IdempotentRequest{Key: utils.RandomString(16), Method: http.MethodPost, Path: "/reserve-products", RequestHash: "a"}
+
+	// Новый ключ закрепляется за запросом
+	stored, err := controller.ClaimIdempotencyKey(store, req, time.Hour)
+	if err != nil || stored != nil {
+		t.Fatalf("Expected new key to be claimed, got %+v and %v", stored, err)
+	}
+
+	// Пока запрос выполняется, повтор получает конфликт
+	_, err = controller.ClaimIdempotencyKey(store, req, time.Hour)
+	if !errors.Is(err, controller.ErrIdempotencyKeyInProgress) {
+		t.Errorf("Expected ErrIdempotencyKeyInProgress, got %v", err)
+	}
+
+	// После сохранения ответа повтор получает его
+	req.Status = http.StatusCreated
+	req.ContentType = "application/json"
+	req.Body = []byte(`{"id":1}`)
+	err = controller.SaveIdempotentResponse(store, req)
+	if err != nil {
+		t.Fatal(err)
+	}
+	stored, err = controller.ClaimIdempotencyKey(store, &controller.IdempotentRequest{Key: req.Key, Method: req.Method, Path: req.Path, RequestHash: "a"}, time.Hour)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if stored == nil || stored.Status != http.StatusCreated || string(stored.Body) != `{"id":1}` {
+		t.Errorf("Expected stored response to be replayed, got %+v", stored)
+	}
+
+	// Тот же ключ с другим телом запроса отклоняется
+	_, err = controller.ClaimIdempotencyKey(store, &controller.IdempotentRequest{Key: req.Key, Method: req.Method, Path: req.Path, RequestHash: "b"}, time.Hour)
+	if !errors.Is(err, controller.ErrIdempotencyKeyReused) {
+		t.Errorf("Expected ErrIdempotencyKeyReused, got %v", err)
+	}
+}
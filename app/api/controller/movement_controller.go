@@ -0,0 +1,91 @@
+package controller
+
+import "time"
+
+// Виды движения остатков
+const (
+	MovementCreate  = "create"
+	MovementUpdate  = "update"
+	MovementDelete  = "delete"
+	MovementReserve = "reserve"
+	MovementRelease = "release"
+	MovementFulfill = "fulfill"
+	MovementExpire  = "expire"
+	MovementReceipt = "receipt"
+	MovementAdjust  = "adjust"
+
+	MovementTransferOut = "transfer_out"
+	MovementTransferIn  = "transfer_in"
+)
+
+// StockMovement запись журнала движения остатков.
+// QuantityDelta - изменение физического остатка, ReservedDelta - изменение резерва.
+// ReservationID, TransferID, ReceiptID и AdjustmentID ссылаются на операцию, вызвавшую движение
+type StockMovement struct {
+	ID            int64     `json:"id"`
+	ProductID     int       `json:"product_id"`
+	WarehouseID   int       `json:"warehouse_id"`
+	Code          string    `json:"code"`
+	Kind          string    `json:"kind"`
+	QuantityDelta int       `json:"quantity_delta"`
+	ReservedDelta int       `json:"reserved_delta"`
+	ReservationID *int      `json:"reservation_id,omitempty"`
+	TransferID    *int      `json:"transfer_id,omitempty"`
+	ReceiptID     *int      `json:"receipt_id,omitempty"`
+	AdjustmentID  *int      `json:"adjustment_id,omitempty"`
+	CreatedAt     time.Time `json:"created_at"`
+}
+
+// MovementFilter фильтры и пагинация журнала движения остатков, нулевые значения не фильтруют.
+// From включительно, To не включительно
+type MovementFilter struct {
+	ProductID   int       `form:"product_id"`
+	WarehouseID int       `form:"warehouse_id"`
+	Code        string    `form:"code"`
+	Kind        string    `form:"kind"`
+	From        time.Time `form:"from"`
+	To          time.Time `form:"to"`
+	Limit       int       `form:"limit"`
+	Offset      int       `form:"offset"`
+}
+
+// MovementPage страница журнала движения остатков
+type MovementPage struct {
+	Items  []StockMovement `json:"items"`
+	Total  int             `json:"total"`
+	Limit  int             `json:"limit"`
+	Offset int             `json:"offset"`
+}
+
+//	@Summary		List stock movements
+//	@Description	List stock movements by product, warehouse, code, kind and time range, newest first.
+//	@Tags			stock-movements
+//	@Produce		json
+//	@Param			product_id		query		int		false	"Product ID"
+//	@Param			warehouse_id	query		int		false	"Warehouse ID"
+//	@Param			code			query		string	false	"Product code"
+//	@Param			kind			query		string	false	"Movement kind"
+//	@Param			from			query		string	false	"From time, RFC3339, inclusive"
+//	@Param			to				query		string	false	"To time, RFC3339, exclusive"
+//	@Param			limit			query		int		false	"Page size"
+//	@Param			offset			query		int		false	"Page offset"
+//	@Success		200				{object}	MovementPage
+//	@Failure		400				{object}	route.ErrorResponse	"Invalid request format"
+//	@Failure		422				{object}	route.ErrorResponse	"Invalid pagination"
+//	@Failure		500				{object}	route.ErrorResponse	"Internal server error"
+//	@Router			/stock-movements [get]
+//
+// ListStockMovements возвращает страницу журнала движения остатков
+func ListStockMovements(s Store, f *MovementFilter) (*MovementPage, error) {
+	filter := MovementFilter{}
+	if f != nil {
+		filter = *f
+	}
+	limit, err := pageLimit(filter.Limit, filter.Offset)
+	if err != nil {
+		return nil, err
+	}
+	filter.Limit = limit
+
+	return s.Movements().List(filter)
+}
@@ -0,0 +1,71 @@
+package controller_test
+
+import (
+	"testing"
+
+	"lamoda-test/api/controller"
+	"lamoda-test/utils"
+)
+
+func TestListStockMovements(t *testing.T) {
+	store := newStore(t)
+
+	// Создаем склад и продукт
+	w := &controller.Warehouse{
+		Name:        utils.RandomString(6),
+		IsAvailable: true,
+	}
+	err := controller.CreateWarehouse(store, w)
+	if err != nil {
+		t.Fatal(err)
+	}
+	p := &controller.Product{
+		Name:        utils.RandomString(6),
+		Size:        utils.RandomSize(),
+		Code:        utils.RandomString(6),
+		Quantity:    5,
+		WarehouseID: w.ID,
+	}
+	err = controller.CreateProduct(store, p)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// Резервируем и выдаем две единицы
+	r := &controller.Reservation{
+		OrderRef: utils.RandomString(6),
+		Lines:    []controller.ReservationLine{{Code: p.Code, Quantity: 2}},
+	}
+	err = controller.ReserveProducts(store, r)
+	if err != nil {
+		t.Fatal(err)
+	}
+	_, err = controller.FulfillReservation(store, r.ID)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	page, err := controller.ListStockMovements(store, &controller.MovementFilter{ProductID: p.ID})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// Записи идут от новых к старым
+	wantKinds := []string{controller.MovementFulfill, controller.MovementReserve, controller.MovementCreate}
+	if page.Total != len(wantKinds) || len(page.Items) != len(wantKinds) {
+		t.Fatalf("Expected %d movements, got %+v", len(wantKinds), page)
+	}
+	quantity, reserved := 0, 0
+	for i, m := range page.Items {
+		if m.Kind != wantKinds[i] {
+			t.Errorf("Expected movement %d to be %q, got %q", i, wantKinds[i], m.Kind)
+		}
+		quantity += m.QuantityDelta
+		reserved += m.ReservedDelta
+	}
+
+	// Сумма движений совпадает с текущим остатком
+	if quantity != 3 || reserved != 0 {
+		t.Errorf("Expected journal to sum to quantity 3 and reserved 0, got %d and %d", quantity, reserved)
+	}
+}
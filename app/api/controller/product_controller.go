@@ -0,0 +1,215 @@
+package controller
+
+import "strings"
+
+var (
+	// ErrProductNotFound возвращается, если продукта с таким ID или кодом нет
+	ErrProductNotFound = &Error{Kind: KindNotFound, Code: "product_not_found", Message: "product not found"}
+	// ErrProductReserved возвращается при попытке удалить продукт с активным резервом
+	ErrProductReserved = &Error{Kind: KindConflict, Code: "product_reserved", Message: "product has reserved units"}
+	// ErrProductExists возвращается, если продукт с таким кодом уже есть на складе
+	ErrProductExists = &Error{Kind: KindConflict, Code: "product_exists", Message: "product already exists on the warehouse"}
+	// ErrNegativeQuantity возвращается при попытке задать отрицательный остаток
+	ErrNegativeQuantity = &Error{Kind: KindValidation, Code: "negative_quantity", Message: "product quantity must not be negative"}
+	// ErrQuantityBelowReserved возвращается при попытке задать остаток меньше зарезервированного
+	ErrQuantityBelowReserved = &Error{Kind: KindConflict, Code: "quantity_below_reserved", Message: "product quantity is below reserved quantity"}
+	// ErrInvalidPagination возвращается для отрицательных limit или offset
+	ErrInvalidPagination = &Error{Kind: KindValidation, Code: "invalid_pagination", Message: "limit and offset must not be negative"}
+	// ErrInvalidSort возвращается для неизвестного поля сортировки
+	ErrInvalidSort = &Error{Kind: KindValidation, Code: "invalid_sort", Message: "unknown sort field"}
+)
+
+// Ограничения размера страницы списка продуктов
+const (
+	DefaultProductLimit = 50
+	MaxProductLimit     = 1000
+)
+
+// productSortFields поля, по которым можно сортировать список продуктов
+var productSortFields = map[string]bool{
+	"id":        true,
+	"name":      true,
+	"code":      true,
+	"size":      true,
+	"quantity":  true,
+	"reserved":  true,
+	"available": true,
+}
+
+// ProductFilter фильтры, сортировка и пагинация списка продуктов.
+// Name ищет подстроку без учета регистра, OutOfStock оставляет продукты без доступного остатка.
+// Sort - поле из productSortFields, префикс "-" задает сортировку по убыванию, при равенстве продукты идут по ID
+type ProductFilter struct {
+	WarehouseID int    `form:"warehouse_id"`
+	Name        string `form:"name"`
+	Size        string `form:"size"`
+	MinQuantity *int   `form:"min_quantity"`
+	MaxQuantity *int   `form:"max_quantity"`
+	OutOfStock  bool   `form:"out_of_stock"`
+	Sort        string `form:"sort"`
+	Limit       int    `form:"limit"`
+	Offset      int    `form:"offset"`
+}
+
+// ProductPage страница списка продуктов с общим количеством подходящих под фильтр
+type ProductPage struct {
+	Items  []Product `json:"items"`
+	Total  int       `json:"total"`
+	Limit  int       `json:"limit"`
+	Offset int       `json:"offset"`
+}
+
+// ProductPatch изменяемые поля продукта, nil - поле не меняется.
+// Код и склад не меняются: для перемещения между складами есть отдельные операции.
+// Проверяются только переданные поля, по тем же правилам, что и в Product.
+// omitempty у указателя пропускает только nil, поэтому пустое название запрещено через min
+type ProductPatch struct {
+	Name     *string `json:"name" validate:"omitempty,min=1,max=255"`
+	Size     *string `json:"size" validate:"omitempty,product_size"`
+	Quantity *int    `json:"quantity" validate:"omitempty,min=0"`
+}
+
+//	@Summary		List products
+//	@Description	List products with filters, sorting and limit/offset pagination.
+//	@Tags			products
+//	@Produce		json
+//	@Param			warehouse_id	query		int		false	"Warehouse ID"
+//	@Param			name			query		string	false	"Name substring"
+//	@Param			size			query		string	false	"Size"
+//	@Param			min_quantity	query		int		false	"Minimum on-hand quantity"
+//	@Param			max_quantity	query		int		false	"Maximum on-hand quantity"
+//	@Param			out_of_stock	query		bool	false	"Only products without available units"
+//	@Param			sort			query		string	false	"Sort field, prefix - for descending"
+//	@Param			limit			query		int		false	"Page size"
+//	@Param			offset			query		int		false	"Page offset"
+//	@Success		200				{object}	ProductPage
+//	@Failure		400				{object}	route.ErrorResponse	"Invalid request format"
+//	@Failure		422				{object}	route.ErrorResponse	"Invalid pagination or sort"
+//	@Failure		500				{object}	route.ErrorResponse	"Internal server error"
+//	@Router			/products [get]
+//
+// ListProducts возвращает страницу продуктов, подходящих под фильтр
+func ListProducts(s Store, f *ProductFilter) (*ProductPage, error) {
+	filter := ProductFilter{}
+	if f != nil {
+		filter = *f
+	}
+	limit, err := pageLimit(filter.Limit, filter.Offset)
+	if err != nil {
+		return nil, err
+	}
+	filter.Limit = limit
+
+	// Направление сортировки задается префиксом, само поле должно быть известно
+	if filter.Sort != "" && !productSortFields[strings.TrimPrefix(filter.Sort, "-")] {
+		return nil, ErrInvalidSort
+	}
+
+	return s.Products().List(filter)
+}
+
+//	@Summary		Get a product
+//	@Description	Get a product by its ID.
+//	@Tags			products
+//	@Produce		json
+//	@Param			id	path		int	true	"Product ID"
+//	@Success		200	{object}	Product
+//	@Failure		400	{object}	route.ErrorResponse	"Invalid request format"
+//	@Failure		404	{object}	route.ErrorResponse	"Product not found"
+//	@Failure		500	{object}	route.ErrorResponse	"Internal server error"
+//	@Router			/products/{id} [get]
+//
+// GetProduct возвращает продукт по ID
+func GetProduct(s Store, id int) (*Product, error) {
+	return s.Products().Get(id)
+}
+
+//	@Summary		Get products by code
+//	@Description	Get a product by its code in every warehouse that stocks it.
+//	@Tags			products
+//	@Produce		json
+//	@Param			code	path		string	true	"Product code"
+//	@Success		200		{array}		Product
+//	@Failure		404		{object}	route.ErrorResponse	"Product not found"
+//	@Failure		500		{object}	route.ErrorResponse	"Internal server error"
+//	@Router			/products/by-code/{code} [get]
+//
+// GetProductsByCode возвращает продукт с заданным кодом на всех складах
+func GetProductsByCode(s Store, code string) ([]Product, error) {
+	products, err := s.Products().ListByCode(code)
+	if err != nil {
+		return nil, err
+	}
+	if len(products) == 0 {
+		return nil, ErrProductNotFound.WithProduct(code)
+	}
+
+	return products, nil
+}
+
+//	@Summary		Update a product
+//	@Description	Partially update a product: name, size or on-hand quantity. Quantity cannot drop below reserved units.
+//	@Tags			products
+//	@Accept			json
+//	@Produce		json
+//	@Param			id		path		int				true	"Product ID"
+//	@Param			product	body		ProductPatch	true	"Fields to update"
+//	@Success		200		{object}	Product
+//	@Failure		400		{object}	route.ErrorResponse	"Invalid request format"
+//	@Failure		404		{object}	route.ErrorResponse	"Product not found"
+//	@Failure		409		{object}	route.ErrorResponse	"Quantity is below reserved quantity"
+//	@Failure		422		{object}	route.ErrorResponse	"Validation failed"
+//	@Failure		500		{object}	route.ErrorResponse	"Internal server error"
+//	@Router			/products/{id} [patch]
+//
+// UpdateProduct частично обновляет продукт
+func UpdateProduct(s Store, id int, patch *ProductPatch) (*Product, error) {
+	// Остальные поля продукта могли быть сохранены до появления проверок и не меняются
+	if err := validateStruct(patch); err != nil {
+		return nil, err
+	}
+
+	var p *Product
+	err := s.InTx(func(tx Repositories) error {
+		// Блокируем продукт, чтобы резерв не изменился до проверки остатка
+		var err error
+		p, err = tx.Products().GetForUpdate(id)
+		if err != nil {
+			return err
+		}
+
+		if patch.Name != nil {
+			p.Name = *patch.Name
+		}
+		if patch.Size != nil {
+			p.Size = *patch.Size
+		}
+		delta := 0
+		if patch.Quantity != nil {
+			delta = *patch.Quantity - p.Quantity
+			p.Quantity = *patch.Quantity
+		}
+
+		// Зарезервированные единицы должны остаться на складе
+		if p.Quantity < p.ReservedQuantity {
+			return ErrQuantityBelowReserved.WithProduct(p.Code)
+		}
+		p.Available = p.Quantity - p.ReservedQuantity
+
+		if err := tx.Products().Update(p); err != nil {
+			return err
+		}
+
+		// Изменение остатка попадает в журнал
+		if delta != 0 {
+			return tx.Movements().Record(StockMovement{ProductID: p.ID, WarehouseID: p.WarehouseID, Code: p.Code, Kind: MovementUpdate, QuantityDelta: delta})
+		}
+
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return p, nil
+}
@@ -0,0 +1,214 @@
+package controller_test
+
+import (
+	"errors"
+	"testing"
+
+	"lamoda-test/api/controller"
+	"lamoda-test/utils"
+)
+
+func TestUpdateProduct(t *testing.T) {
+	store := newStore(t)
+
+	// Создаем склад и продукт с резервом в 2 единицы
+	w := &controller.Warehouse{
+		Name:        utils.RandomString(6),
+		IsAvailable: true,
+	}
+	err := controller.CreateWarehouse(store, w)
+	if err != nil {
+		t.Fatal(err)
+	}
+	p := &controller.Product{
+		Name:        utils.RandomString(6),
+		Size:        utils.RandomSize(),
+		Code:        utils.RandomString(6),
+		Quantity:    5,
+		WarehouseID: w.ID,
+	}
+	err = controller.CreateProduct(store, p)
+	if err != nil {
+		t.Fatal(err)
+	}
+	err = controller.ReserveProducts(store, &controller.Reservation{
+		OrderRef: utils.RandomString(6),
+		Lines:    []controller.ReservationLine{{Code: p.Code, Quantity: 2}},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// Остаток нельзя уменьшить ниже резерва
+	quantity := 1
+	_, err = controller.UpdateProduct(store, p.ID, &controller.ProductPatch{Quantity: &quantity})
+	if !errors.Is(err, controller.ErrQuantityBelowReserved) {
+		t.Errorf("Expected ErrQuantityBelowReserved, got %v", err)
+	}
+
+	// Меняем название и остаток, размер не меняется
+	name := utils.RandomString(6)
+	quantity = 3
+	updated, err := controller.UpdateProduct(store, p.ID, &controller.ProductPatch{Name: &name, Quantity: &quantity})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if updated.Name != name || updated.Size != p.Size || updated.Quantity != 3 || updated.Available != 1 {
+		t.Errorf("Unexpected product after update: %+v", updated)
+	}
+
+	got, err := controller.GetProduct(store, p.ID)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if *got != *updated {
+		t.Errorf("Expected stored product %+v, got %+v", updated, got)
+	}
+
+	// Продукт с резервом удалить нельзя
+	err = controller.DeleteProduct(store, p.ID)
+	if !errors.Is(err, controller.ErrProductReserved) {
+		t.Errorf("Expected ErrProductReserved, got %v", err)
+	}
+}
+
+func TestUpdateProductLegacyRow(t *testing.T) {
+	store := newStore(t)
+
+	w := &controller.Warehouse{Name: utils.RandomString(6), IsAvailable: true}
+	err := controller.CreateWarehouse(store, w)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// Продукт сохранен до появления проверок: без названия и с кодом, который сейчас не пройдет проверку
+	p := &controller.Product{Code: "legacy code", Quantity: 1, WarehouseID: w.ID}
+	err = store.Products().Create(p)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// Остаток меняется, непереданные поля не проверяются
+	quantity := 5
+	updated, err := controller.UpdateProduct(store, p.ID, &controller.ProductPatch{Quantity: &quantity})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if updated.Quantity != 5 || updated.Code != p.Code {
+		t.Errorf("Unexpected product after update: %+v", updated)
+	}
+
+	// Переданные поля проверяются
+	empty := ""
+	_, err = controller.UpdateProduct(store, p.ID, &controller.ProductPatch{Name: &empty})
+	if !errors.Is(err, controller.ErrValidation) {
+		t.Errorf("Expected ErrValidation, got %v", err)
+	}
+}
+
+func TestGetProductsByCode(t *testing.T) {
+	store := newStore(t)
+
+	// Один код на двух складах
+	code := utils.RandomString(6)
+	for i := 0; i < 2; i++ {
+		w := &controller.Warehouse{
+			Name:        utils.RandomString(6),
+			IsAvailable: true,
+		}
+		err := controller.CreateWarehouse(store, w)
+		if err != nil {
+			t.Fatal(err)
+		}
+		err = controller.CreateProduct(store, &controller.Product{
+			Name:        utils.RandomString(6),
+			Size:        utils.RandomSize(),
+			Code:        code,
+			Quantity:    1,
+			WarehouseID: w.ID,
+		})
+		if err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	products, err := controller.GetProductsByCode(store, code)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(products) != 2 {
+		t.Errorf("Expected 2 products, got %d", len(products))
+	}
+
+	_, err = controller.GetProductsByCode(store, "invalid-code")
+	if !errors.Is(err, controller.ErrProductNotFound) {
+		t.Errorf("Expected ErrProductNotFound, got %v", err)
+	}
+}
+
+func TestListProductsFilters(t *testing.T) {
+	store := newStore(t)
+
+	// Отдельный склад, чтобы фильтры не зависели от других тестов
+	w := &controller.Warehouse{
+		Name:        utils.RandomString(6),
+		IsAvailable: true,
+	}
+	err := controller.CreateWarehouse(store, w)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for i, name := range []string{"Red Shirt", "red_dress", "Blue Shirt"} {
+		err = controller.CreateProduct(store, &controller.Product{
+			Name:        name,
+			Size:        "M",
+			Code:        utils.RandomString(6),
+			Quantity:    i,
+			WarehouseID: w.ID,
+		})
+		if err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	// Подстрока без учета регистра, сортировка по убыванию остатка
+	page, err := controller.ListProducts(store, &controller.ProductFilter{WarehouseID: w.ID, Name: "red", Sort: "-quantity"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if page.Total != 2 || len(page.Items) != 2 || page.Items[0].Name != "red_dress" {
+		t.Errorf("Unexpected page for name filter: %+v", page)
+	}
+
+	// "_" ищется как символ, а не как шаблон
+	page, err = controller.ListProducts(store, &controller.ProductFilter{WarehouseID: w.ID, Name: "d_d"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if page.Total != 1 {
+		t.Errorf("Expected 1 product for escaped name filter, got %d", page.Total)
+	}
+
+	// Только продукты без доступного остатка
+	page, err = controller.ListProducts(store, &controller.ProductFilter{WarehouseID: w.ID, OutOfStock: true})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if page.Total != 1 || page.Items[0].Name != "Red Shirt" {
+		t.Errorf("Unexpected page for out of stock filter: %+v", page)
+	}
+
+	// Пагинация возвращает общее количество независимо от размера страницы
+	page, err = controller.ListProducts(store, &controller.ProductFilter{WarehouseID: w.ID, Limit: 1, Offset: 1})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if page.Total != 3 || len(page.Items) != 1 || page.Items[0].Name != "red_dress" {
+		t.Errorf("Unexpected page for limit and offset: %+v", page)
+	}
+
+	_, err = controller.ListProducts(store, &controller.ProductFilter{Sort: "price"})
+	if !errors.Is(err, controller.ErrInvalidSort) {
+		t.Errorf("Expected ErrInvalidSort, got %v", err)
+	}
+}
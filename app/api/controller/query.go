@@ -0,0 +1,16 @@
+package controller
+
+// pageLimit проверяет пагинацию и возвращает размер страницы с учетом ограничений
+func pageLimit(limit, offset int) (int, error) {
+	if limit < 0 || offset < 0 {
+		return 0, ErrInvalidPagination
+	}
+	if limit == 0 {
+		return DefaultProductLimit, nil
+	}
+	if limit > MaxProductLimit {
+		return MaxProductLimit, nil
+	}
+
+	return limit, nil
+}
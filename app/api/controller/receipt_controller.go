@@ -0,0 +1,183 @@
+package controller
+
+import (
+	"sort"
+	"time"
+)
+
+var (
+	// ErrReceiptNotFound возвращается, если приемки с таким ID нет
+	ErrReceiptNotFound = &Error{Kind: KindNotFound, Code: "receipt_not_found", Message: "receipt not found"}
+	// ErrSupplierRefRequired возвращается, если не указана ссылка на поставщика
+	ErrSupplierRefRequired = &Error{Kind: KindValidation, Code: "supplier_ref_required", Message: "empty supplier reference"}
+	// ErrWarehouseRequired возвращается, если в строке приемки не указан склад
+	ErrWarehouseRequired = &Error{Kind: KindValidation, Code: "warehouse_required", Message: "warehouse_id is required"}
+	// ErrProductCodeRequired возвращается, если в строке приемки не указан код продукта
+	ErrProductCodeRequired = &Error{Kind: KindValidation, Code: "product_code_required", Message: "empty product code"}
+)
+
+// Receipt приемка товара от поставщика
+type Receipt struct {
+	ID          int           `json:"id"`
+	SupplierRef string        `json:"supplier_ref"`
+	Lines       []ReceiptLine `json:"lines"`
+	CreatedAt   time.Time     `json:"created_at"`
+}
+
+// ReceiptLine строка приемки: сколько единиц продукта поступило на склад.
+// Name и Size используются только при создании нового продукта на складе
+type ReceiptLine struct {
+	ProductID   int    `json:"product_id"`
+	WarehouseID int    `json:"warehouse_id"`
+	Code        string `json:"code"`
+	Name        string `json:"name,omitempty"`
+	Size        string `json:"size,omitempty"`
+	Quantity    int    `json:"quantity"`
+}
+
+//	@Summary		Receive goods
+//	@Description	Records an inbound shipment: increases stock for each line, creating products that are new to the warehouse.
+//	@Tags			receipts
+//	@Accept			json
+//	@Produce		json
+//	@Param			receipt	body		Receipt	true	"Supplier reference and product lines"
+//	@Success		201		{object}	Receipt
+//	@Failure		400		{object}	route.ErrorResponse	"Invalid request format"
+//	@Failure		404		{object}	route.ErrorResponse	"Warehouse not found"
+//	@Failure		409		{object}	route.ErrorResponse	"Warehouse unavailable"
+//	@Failure		422		{object}	route.ErrorResponse	"Invalid supplier reference or lines"
+//	@Failure		500		{object}	route.ErrorResponse	"Internal server error"
+//	@Router			/receipts [post]
+//
+// CreateReceipt увеличивает остатки по строкам приемки и записывает приемку
+func CreateReceipt(s Store, r *Receipt) error {
+	if r.SupplierRef == "" {
+		return ErrSupplierRefRequired
+	}
+	if len(r.Lines) == 0 {
+		return ErrLinesRequired
+	}
+	for _, l := range r.Lines {
+		if l.Code == "" {
+			return ErrProductCodeRequired
+		}
+		if l.Quantity <= 0 {
+			return ErrInvalidQuantity.WithProduct(l.Code)
+		}
+		if l.WarehouseID == 0 {
+			return ErrWarehouseRequired.WithProduct(l.Code)
+		}
+	}
+
+	lines := mergeReceiptLines(r.Lines)
+
+	return s.InTx(func(tx Repositories) error {
+		// Проверяем, что склады доступны
+		checked := map[int]bool{}
+		for _, l := range lines {
+			if checked[l.WarehouseID] {
+				continue
+			}
+			w, err := tx.Warehouses().GetForShare(l.WarehouseID)
+			if err != nil {
+				return err
+			}
+			if !w.IsAvailable {
+				return ErrWarehouseUnavailable.WithWarehouse(w.ID)
+			}
+			checked[w.ID] = true
+		}
+
+		warehouseIDs := map[string][]int{}
+		for _, l := range lines {
+			warehouseIDs[l.Code] = append(warehouseIDs[l.Code], l.WarehouseID)
+		}
+		if err := lockCodes(tx, warehouseIDs); err != nil {
+			return err
+		}
+
+		for i, l := range lines {
+			// Создаем продукт на складе или увеличиваем его остаток
+			p := Product{Name: l.Name, Size: l.Size, Code: l.Code, Quantity: l.Quantity, WarehouseID: l.WarehouseID}
+			existing, err := tx.Products().LockByCode(l.Code, l.WarehouseID)
+			if err != nil {
+				return err
+			}
+			// Новый продукт проверяется так же, как при создании
+			if len(existing) == 0 {
+				if err := validateStruct(&p); err != nil {
+					return err
+				}
+			}
+			if err := tx.Products().Restock(&p); err != nil {
+				return err
+			}
+			lines[i].ProductID = p.ID
+		}
+
+		r.Lines = lines
+		if err := tx.Receipts().Create(r); err != nil {
+			return err
+		}
+
+		for _, l := range lines {
+			err := tx.Movements().Record(StockMovement{ProductID: l.ProductID, WarehouseID: l.WarehouseID, Code: l.Code, Kind: MovementReceipt, QuantityDelta: l.Quantity, ReceiptID: &r.ID})
+			if err != nil {
+				return err
+			}
+		}
+
+		return nil
+	})
+}
+
+//	@Summary		Get a receipt
+//	@Description	Get a goods receipt with its lines by ID.
+//	@Tags			receipts
+//	@Produce		json
+//	@Param			id	path		int	true	"Receipt ID"
+//	@Success		200	{object}	Receipt
+//	@Failure		400	{object}	route.ErrorResponse
+//	@Failure		404	{object}	route.ErrorResponse
+//	@Failure		500	{object}	route.ErrorResponse
+//	@Router			/receipts/{id} [get]
+//
+// GetReceipt возвращает приемку по ID
+func GetReceipt(s Store, id int) (*Receipt, error) {
+	return s.Receipts().Get(id)
+}
+
+// mergeReceiptLines объединяет строки с одинаковым кодом и складом и сортирует их по коду и складу.
+// Название и размер берутся из первой строки, где они указаны
+func mergeReceiptLines(lines []ReceiptLine) []ReceiptLine {
+	type key struct {
+		code        string
+		warehouseID int
+	}
+	byKey := make(map[key]int, len(lines))
+	merged := make([]ReceiptLine, 0, len(lines))
+	for _, l := range lines {
+		k := key{l.Code, l.WarehouseID}
+		if i, ok := byKey[k]; ok {
+			merged[i].Quantity += l.Quantity
+			if merged[i].Name == "" {
+				merged[i].Name = l.Name
+			}
+			if merged[i].Size == "" {
+				merged[i].Size = l.Size
+			}
+			continue
+		}
+		byKey[k] = len(merged)
+		merged = append(merged, ReceiptLine{Code: l.Code, WarehouseID: l.WarehouseID, Name: l.Name, Size: l.Size, Quantity: l.Quantity})
+	}
+
+	sort.Slice(merged, func(i, j int) bool {
+		if merged[i].Code != merged[j].Code {
+			return merged[i].Code < merged[j].Code
+		}
+		return merged[i].WarehouseID < merged[j].WarehouseID
+	})
+
+	return merged
+}
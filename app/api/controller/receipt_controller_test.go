@@ -0,0 +1,170 @@
+package controller_test
+
+import (
+	"errors"
+	"sync"
+	"testing"
+
+	"lamoda-test/api/controller"
+	"lamoda-test/utils"
+)
+
+func TestCreateReceipt(t *testing.T) {
+	store := newStore(t)
+
+	// Создаем склад и продукт
+	w := &controller.Warehouse{Name: utils.RandomString(6), IsAvailable: true}
+	err := controller.CreateWarehouse(store, w)
+	if err != nil {
+		t.Fatal(err)
+	}
+	p := &controller.Product{
+		Name:        utils.RandomString(6),
+		Size:        utils.RandomSize(),
+		Code:        utils.RandomString(6),
+		Quantity:    2,
+		WarehouseID: w.ID,
+	}
+	err = controller.CreateProduct(store, p)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// Принимаем существующий продукт двумя строками и новый продукт
+	newCode := utils.RandomString(6)
+	r := &controller.Receipt{
+		SupplierRef: utils.RandomString(6),
+		Lines: []controller.ReceiptLine{
+			{Code: p.Code, Quantity: 3, WarehouseID: w.ID},
+			{Code: newCode, Name: "new", Size: "M", Quantity: 4, WarehouseID: w.ID},
+			{Code: p.Code, Quantity: 1, WarehouseID: w.ID},
+		},
+	}
+	err = controller.CreateReceipt(store, r)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	existing, err := controller.GetProduct(store, p.ID)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if existing.Quantity != 6 {
+		t.Errorf("Expected quantity 6 after receipt, got %d", existing.Quantity)
+	}
+	created, err := controller.GetProductsByCode(store, newCode)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(created) != 1 || created[0].Quantity != 4 || created[0].Name != "new" {
+		t.Errorf("Expected new product with quantity 4, got %+v", created)
+	}
+
+	// Приемка сохраняется с объединенными строками
+	saved, err := controller.GetReceipt(store, r.ID)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if saved.SupplierRef != r.SupplierRef || len(saved.Lines) != 2 {
+		t.Errorf("Expected receipt with 2 lines, got %+v", saved)
+	}
+}
+
+func TestCreateReceiptValidation(t *testing.T) {
+	store := newStore(t)
+
+	w := &controller.Warehouse{Name: utils.RandomString(6), IsAvailable: true}
+	err := controller.CreateWarehouse(store, w)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// Строка без кода
+	err = controller.CreateReceipt(store, &controller.Receipt{
+		SupplierRef: utils.RandomString(6),
+		Lines:       []controller.ReceiptLine{{Name: "new", Quantity: 1, WarehouseID: w.ID}},
+	})
+	if !errors.Is(err, controller.ErrProductCodeRequired) {
+		t.Errorf("Expected ErrProductCodeRequired, got %v", err)
+	}
+
+	// Новый продукт проверяется по тем же правилам, что и при создании
+	err = controller.CreateReceipt(store, &controller.Receipt{
+		SupplierRef: utils.RandomString(6),
+		Lines:       []controller.ReceiptLine{{Code: "bad code!!", Quantity: 1, WarehouseID: w.ID}},
+	})
+	var e *controller.Error
+	if !errors.As(err, &e) || !errors.Is(err, controller.ErrValidation) {
+		t.Fatalf("Expected ErrValidation, got %v", err)
+	}
+	if len(e.Fields) != 2 || e.Fields[0].Field != "name" || e.Fields[1].Field != "code" {
+		t.Errorf("Expected name and code fields, got %+v", e.Fields)
+	}
+
+	page, err := controller.GetRemainingProducts(store, w.ID, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if page.Total != 0 {
+		t.Errorf("Expected no products after rejected receipts, got %d", page.Total)
+	}
+}
+
+func TestCreateReceiptConcurrentWithReserve(t *testing.T) {
+	store := newStore(t)
+
+	// Строка продукта на втором складе создана раньше, поэтому порядок складов и ID продуктов не совпадает
+	var warehouses []*controller.Warehouse
+	for i := 0; i < 2; i++ {
+		w := &controller.Warehouse{Name: utils.RandomString(6), IsAvailable: true}
+		err := controller.CreateWarehouse(store, w)
+		if err != nil {
+			t.Fatal(err)
+		}
+		warehouses = append(warehouses, w)
+	}
+	code := utils.RandomString(6)
+	for _, w := range []*controller.Warehouse{warehouses[1], warehouses[0]} {
+		err := controller.CreateProduct(store, &controller.Product{
+			Name:        utils.RandomString(6),
+			Size:        utils.RandomSize(),
+			Code:        code,
+			Quantity:    100,
+			WarehouseID: w.ID,
+		})
+		if err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	// Приемка на оба склада и параллельное резервирование кода не должны взаимоблокироваться
+	for i := 0; i < 20; i++ {
+		var wg sync.WaitGroup
+		var receiptErr, reserveErr error
+		wg.Add(2)
+		go func() {
+			defer wg.Done()
+			receiptErr = controller.CreateReceipt(store, &controller.Receipt{
+				SupplierRef: utils.RandomString(6),
+				Lines: []controller.ReceiptLine{
+					{Code: code, Quantity: 1, WarehouseID: warehouses[0].ID},
+					{Code: code, Quantity: 1, WarehouseID: warehouses[1].ID},
+				},
+			})
+		}()
+		go func() {
+			defer wg.Done()
+			reserveErr = controller.ReserveProducts(store, &controller.Reservation{
+				OrderRef: utils.RandomString(6),
+				Lines:    []controller.ReservationLine{{Code: code, Quantity: 1}},
+			})
+		}()
+		wg.Wait()
+		if receiptErr != nil {
+			t.Fatalf("Expected receipt to succeed, got %v", receiptErr)
+		}
+		if reserveErr != nil {
+			t.Fatalf("Expected reserve to succeed, got %v", reserveErr)
+		}
+	}
+}
@@ -0,0 +1,142 @@
+package controller
+
+import "time"
+
+// WarehouseRepository хранилище складов.
+// Методы GetFor* внутри транзакции блокируют склад до ее завершения.
+// Операции с остатками блокируют склады на чтение раньше их продуктов, чтобы доступность складов
+// не изменилась до конца транзакции, а удаление склада не взаимоблокировалось с этими операциями
+type WarehouseRepository interface {
+	// Create сохраняет склад и заполняет его ID
+	Create(w *Warehouse) error
+	// List возвращает все склады по возрастанию ID
+	List() ([]Warehouse, error)
+	// Get возвращает склад или ErrWarehouseNotFound
+	Get(id int) (*Warehouse, error)
+	// GetForShare возвращает склад и запрещает его изменение до конца транзакции
+	GetForShare(id int) (*Warehouse, error)
+	// GetForUpdate возвращает склад и блокирует его до конца транзакции
+	GetForUpdate(id int) (*Warehouse, error)
+	// Update сохраняет все поля склада
+	Update(w *Warehouse) error
+	// Delete удаляет склад, ссылки на него в истории операций обнуляются
+	Delete(id int) error
+}
+
+// ProductRepository хранилище продуктов.
+// Методы Lock* и GetForUpdate внутри транзакции блокируют строки до ее завершения.
+// Чтобы параллельные операции не взаимоблокировались, все они блокируют продукты в порядке кодов,
+// строки одного кода - в порядке ID, а резервирования - после всех своих продуктов
+type ProductRepository interface {
+	// Create сохраняет продукт и заполняет его ID.
+	// Возвращает ErrProductExists, если код уже есть на складе, и ErrWarehouseNotFound для неизвестного склада
+	Create(p *Product) error
+	// Restock создает продукт на складе или увеличивает остаток существующего на p.Quantity.
+	// Название и размер используются только при создании, p.ID получает ID строки
+	Restock(p *Product) error
+	// CreateIfMissing создает продукт на складе, если его там нет.
+	// Существующий продукт не меняется и не блокируется
+	CreateIfMissing(p *Product) error
+	// Get возвращает продукт или ErrProductNotFound
+	Get(id int) (*Product, error)
+	// GetForUpdate возвращает продукт или ErrProductNotFound и блокирует его
+	GetForUpdate(id int) (*Product, error)
+	// List возвращает страницу продуктов по фильтру с уже проверенными сортировкой и пагинацией.
+	// При равенстве поля сортировки продукты упорядочиваются по ID, чтобы страницы не пересекались
+	List(f ProductFilter) (*ProductPage, error)
+	// ListByCode возвращает продукт на всех складах по возрастанию ID склада
+	ListByCode(code string) ([]Product, error)
+	// LockByCode блокирует строки продукта на складах warehouseIDs, без складов - на всех
+	LockByCode(code string, warehouseIDs ...int) ([]Product, error)
+	// LockByWarehouse блокирует все продукты склада в порядке кодов
+	LockByWarehouse(warehouseID int) ([]Product, error)
+	// LockStock блокирует строки продукта на складе warehouseID или на всех складах, если он равен 0,
+	// и возвращает их остатки вместе со складами, склады предварительно блокируются на чтение
+	LockStock(code string, warehouseID int) ([]StockLevel, error)
+	// Update сохраняет название, размер и остаток продукта
+	Update(p *Product) error
+	// AddStock изменяет физический остаток и резерв продукта на заданные величины
+	AddStock(id, quantityDelta, reservedDelta int) error
+	// Delete удаляет продукт, ссылки на него в истории операций обнуляются
+	Delete(id int) error
+}
+
+// ReservationRepository хранилище резервирований
+type ReservationRepository interface {
+	// Create сохраняет резервирование со строками, заполняет ID и время создания
+	Create(r *Reservation) error
+	// Get возвращает резервирование со строками или ErrReservationNotFound
+	Get(id int) (*Reservation, error)
+	// GetForUpdate возвращает резервирование со строками или ErrReservationNotFound и блокирует его
+	GetForUpdate(id int) (*Reservation, error)
+	// SetStatus меняет статус резервирования и обновляет r.Status и r.UpdatedAt
+	SetStatus(r *Reservation, status string) error
+	// ListExpired возвращает ID активных резервирований, срок которых истек к now, начиная с самых старых
+	ListExpired(now time.Time) ([]int, error)
+	// ListHolding возвращает активные резервирования со строками продукта productID от новых к старым и блокирует их
+	ListHolding(productID int) ([]Reservation, error)
+	// ReduceLines уменьшает строки резервирования с продуктом productID на quantity единиц, строки без единиц удаляются
+	ReduceLines(reservationID, productID, quantity int) error
+}
+
+// MovementRepository журнал движения остатков, записи только добавляются
+type MovementRepository interface {
+	// Record добавляет запись в журнал
+	Record(m StockMovement) error
+	// List возвращает страницу журнала от новых записей к старым, пагинация уже проверена
+	List(f MovementFilter) (*MovementPage, error)
+}
+
+// TransferRepository хранилище перемещений между складами
+type TransferRepository interface {
+	// Create сохраняет перемещение, заполняет ID и время создания
+	Create(t *Transfer) error
+}
+
+// ReceiptRepository хранилище приемок товара
+type ReceiptRepository interface {
+	// Create сохраняет приемку со строками, заполняет ID и время создания
+	Create(r *Receipt) error
+	// Get возвращает приемку со строками или ErrReceiptNotFound
+	Get(id int) (*Receipt, error)
+}
+
+// AdjustmentRepository хранилище корректировок остатков
+type AdjustmentRepository interface {
+	// Create сохраняет корректировку, заполняет ID и время создания
+	Create(a *Adjustment) error
+}
+
+// IdempotencyRepository хранилище ключей идемпотентности
+type IdempotencyRepository interface {
+	// Claim закрепляет ключ за запросом и возвращает nil или уже сохраненный запрос с этим ключом.
+	// Ключи старше ttl и незавершенные запросы старше lockTimeout перед этим освобождаются
+	Claim(req *IdempotentRequest, ttl, lockTimeout time.Duration) (*IdempotentRequest, error)
+	// Save сохраняет ответ на закрепленный запрос
+	Save(req *IdempotentRequest) error
+	// Release освобождает ключ незавершенного запроса
+	Release(req *IdempotentRequest) error
+	// Purge удаляет ключи старше ttl и возвращает их количество
+	Purge(ttl time.Duration) (int, error)
+}
+
+// Repositories хранилища, работающие в одной транзакции
+type Repositories interface {
+	Warehouses() WarehouseRepository
+	Products() ProductRepository
+	Reservations() ReservationRepository
+	Movements() MovementRepository
+	Transfers() TransferRepository
+	Receipts() ReceiptRepository
+	Adjustments() AdjustmentRepository
+}
+
+// Store хранилище данных склада.
+// Вне InTx каждое обращение к хранилищам выполняется отдельно и ничего не блокирует
+type Store interface {
+	Repositories
+	// InTx выполняет fn в транзакции: изменения фиксируются, если fn вернула nil, иначе откатываются
+	InTx(fn func(tx Repositories) error) error
+	// Idempotency хранилище ключей идемпотентности, работает вне транзакций
+	Idempotency() IdempotencyRepository
+}
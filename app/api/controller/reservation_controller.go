@@ -0,0 +1,555 @@
+package controller
+
+import (
+	"errors"
+	"sort"
+	"strconv"
+	"time"
+
+	"lamoda-test/pkg/metrics"
+)
+
+// Статусы резервирования
+const (
+	ReservationActive    = "active"
+	ReservationReleased  = "released"
+	ReservationFulfilled = "fulfilled"
+	ReservationExpired   = "expired"
+)
+
+var (
+	// ErrReservationNotFound возвращается, если резервирования с таким ID нет
+	ErrReservationNotFound = &Error{Kind: KindNotFound, Code: "reservation_not_found", Message: "reservation not found"}
+	// ErrReservationNotActive возвращается при попытке изменить завершенное резервирование
+	ErrReservationNotActive = &Error{Kind: KindConflict, Code: "reservation_not_active", Message: "reservation is not active"}
+	// ErrInvalidQuantity возвращается, если в строке указано неположительное количество
+	ErrInvalidQuantity = &Error{Kind: KindValidation, Code: "invalid_quantity", Message: "product quantity must be positive"}
+	// ErrOutOfStock возвращается, если доступного остатка не хватает
+	ErrOutOfStock = &Error{Kind: KindOutOfStock, Code: "out_of_stock", Message: "product is out of stock"}
+	// ErrOrderRefRequired возвращается, если не указана ссылка на заказ
+	ErrOrderRefRequired = &Error{Kind: KindValidation, Code: "order_ref_required", Message: "empty order reference"}
+	// ErrLinesRequired возвращается, если не передано ни одной строки с продуктом
+	ErrLinesRequired = &Error{Kind: KindValidation, Code: "lines_required", Message: "empty product codes"}
+	// ErrNotReserved возвращается при попытке снять больше единиц, чем зарезервировано
+	ErrNotReserved = &Error{Kind: KindConflict, Code: "not_reserved", Message: "product has no reserved units"}
+	// ErrNothingReserved возвращается в режиме частичного резервирования, если не удалось зарезервировать ни одной строки
+	ErrNothingReserved = &Error{Kind: KindOutOfStock, Code: "nothing_reserved", Message: "no product could be reserved"}
+)
+
+// Результаты строк при частичном резервировании
+const (
+	LineReserved     = "reserved"
+	LineInsufficient = "insufficient"
+	LineUnknownCode  = "unknown_code"
+	LineUnavailable  = "unavailable"
+)
+
+// Reservation структура резервирования.
+// Если задан WarehouseID, продукты резервируются только на этом складе,
+// иначе количество распределяется по доступным складам стратегией Strategy.
+// Если задан ExpiresAt, по его истечении активное резервирование снимается автоматически.
+// Если задан Partial, резервируются только строки, которых хватает, а итог по каждой строке попадает в Results
+// в порядке строк запроса
+type Reservation struct {
+	ID          int               `json:"id"`
+	OrderRef    string            `json:"order_ref"`
+	WarehouseID int               `json:"warehouse_id,omitempty"`
+	Status      string            `json:"status"`
+	Lines       []ReservationLine `json:"lines"`
+	CreatedAt   time.Time         `json:"created_at"`
+	UpdatedAt   time.Time         `json:"updated_at"`
+	ExpiresAt   *time.Time        `json:"expires_at,omitempty"`
+	Results     []LineResult      `json:"results,omitempty"`
+
+	Strategy AllocationStrategy `json:"-"`
+	Partial  bool               `json:"-"`
+}
+
+// ReservationLine строка резервирования: сколько единиц продукта зарезервировано и на каком складе
+type ReservationLine struct {
+	ProductID   int    `json:"product_id"`
+	WarehouseID int    `json:"warehouse_id"`
+	Code        string `json:"code"`
+	Quantity    int    `json:"quantity"`
+}
+
+// LineResult итог резервирования строки корзины в режиме частичного резервирования.
+// Строка резервируется целиком или не резервируется совсем, Available - доступный остаток на момент запроса.
+// Строки с одинаковыми кодом и складом резервируются вместе и получают общий статус
+type LineResult struct {
+	Code        string `json:"code"`
+	WarehouseID int    `json:"warehouse_id,omitempty"`
+	Requested   int    `json:"requested"`
+	Reserved    int    `json:"reserved"`
+	Available   int    `json:"available"`
+	Status      string `json:"status"`
+}
+
+//	@Summary		Reserves products
+//	@Description	Reserves products for an order and returns the created reservation.
+//	@Description	By default the basket is reserved all-or-nothing; with partial=true only lines in stock are reserved and results lists the outcome per line.
+//	@Description	Results follow the order of the request lines, one result per line.
+//	@Tags			reservations
+//	@Accept			json
+//	@Produce		json
+//	@Param			reservation	body		Reservation	true	"Order reference and product lines"
+//	@Success		201			{object}	Reservation
+//	@Failure		400			{object}	route.ErrorResponse
+//	@Failure		404			{object}	route.ErrorResponse
+//	@Failure		409			{object}	route.ErrorResponse
+//	@Failure		422			{object}	route.ErrorResponse
+//	@Failure		500			{object}	route.ErrorResponse
+//	@Router			/reservations [post]
+//
+// ReserveProducts резервирует продукты и создает запись резервирования
+func ReserveProducts(s Store, r *Reservation) error {
+	err := reserveProducts(s, r)
+	if err != nil {
+		metrics.ReservationsFailed.WithLabelValues(AsError(err).Code).Inc()
+		return err
+	}
+
+	metrics.ReservationsCreated.Inc()
+	for _, l := range r.Lines {
+		metrics.UnitsReserved.Add(float64(l.Quantity))
+	}
+
+	return nil
+}
+
+// reserveProducts резервирует продукты, метрики считает ReserveProducts
+func reserveProducts(s Store, r *Reservation) error {
+	if r.OrderRef == "" {
+		return ErrOrderRefRequired
+	}
+	if err := validateLines(r.Lines); err != nil {
+		return err
+	}
+
+	requested := r.Lines
+	lines := mergeLines(r.Lines)
+
+	strategy := r.Strategy
+	if strategy == nil {
+		strategy = FewestSplits{}
+	}
+
+	var reserved []ReservationLine
+	var results []LineResult
+	err := s.InTx(func(tx Repositories) error {
+		// Если склад задан явно, он должен быть доступен
+		if r.WarehouseID != 0 {
+			w, err := tx.Warehouses().GetForShare(r.WarehouseID)
+			if err != nil {
+				return err
+			}
+			if !w.IsAvailable {
+				return ErrWarehouseUnavailable.WithWarehouse(w.ID)
+			}
+		}
+
+		// Строки одного кода на нескольких складах блокируем заранее, одним запросом в порядке ID
+		for i := 1; i < len(lines); i++ {
+			if lines[i].Code != lines[i-1].Code || (i > 1 && lines[i-2].Code == lines[i].Code) {
+				continue
+			}
+			if _, err := tx.Products().LockStock(lines[i].Code, 0); err != nil {
+				return err
+			}
+		}
+
+		// Зарезервируем каждый продукт в цикле, распределяя количество по складам
+		for _, l := range lines {
+			result := LineResult{Code: l.Code, WarehouseID: l.WarehouseID, Requested: l.Quantity}
+
+			// Склад строки важнее склада резервирования
+			warehouseID := l.WarehouseID
+			if warehouseID == 0 {
+				warehouseID = r.WarehouseID
+			}
+			stock, err := lockStock(tx, l.Code, warehouseID)
+			if err != nil {
+				// В режиме частичного резервирования строка пропускается
+				status := lineFailure(err)
+				if !r.Partial || status == "" {
+					return err
+				}
+				result.Status = status
+				results = append(results, result)
+				continue
+			}
+			for _, level := range stock {
+				result.Available += level.Available
+			}
+
+			// Проверяем, хватает ли незарезервированных единиц продукта на доступных складах
+			allocations := strategy.Allocate(stock, l.Quantity)
+			if allocations == nil {
+				// Считаем склады, которые не покрывают строку целиком
+				for _, level := range stock {
+					if level.Available < l.Quantity {
+						metrics.OutOfStock.WithLabelValues(strconv.Itoa(level.Warehouse.ID)).Inc()
+					}
+				}
+				if !r.Partial {
+					return ErrOutOfStock.WithProduct(l.Code)
+				}
+				result.Status = LineInsufficient
+				results = append(results, result)
+				continue
+			}
+			result.Reserved = l.Quantity
+			result.Status = LineReserved
+			results = append(results, result)
+
+			// Увеличиваем резерв, физический остаток не меняется
+			for _, a := range allocations {
+				if err := tx.Products().AddStock(a.ProductID, 0, a.Quantity); err != nil {
+					return err
+				}
+				reserved = append(reserved, ReservationLine{ProductID: a.ProductID, WarehouseID: a.WarehouseID, Code: l.Code, Quantity: a.Quantity})
+			}
+		}
+
+		// Пустое резервирование не создаем
+		if len(reserved) == 0 {
+			return ErrNothingReserved
+		}
+
+		// Сохраняем резервирование и его строки
+		r.Status = ReservationActive
+		r.Lines = reserved
+		if err := tx.Reservations().Create(r); err != nil {
+			return err
+		}
+		for _, l := range reserved {
+			err := tx.Movements().Record(StockMovement{ProductID: l.ProductID, WarehouseID: l.WarehouseID, Code: l.Code, Kind: MovementReserve, ReservedDelta: l.Quantity, ReservationID: &r.ID})
+			if err != nil {
+				return err
+			}
+		}
+
+		return nil
+	})
+	if errors.Is(err, ErrNothingReserved) {
+		r.Results = requestOrder(requested, results)
+	}
+	if err != nil {
+		return err
+	}
+
+	if r.Partial {
+		r.Results = requestOrder(requested, results)
+	}
+
+	return nil
+}
+
+// requestOrder раскладывает итоги объединенных строк по строкам запроса, сохраняя их порядок
+func requestOrder(lines []ReservationLine, merged []LineResult) []LineResult {
+	type key struct {
+		code        string
+		warehouseID int
+	}
+	byKey := make(map[key]LineResult, len(merged))
+	for _, res := range merged {
+		byKey[key{res.Code, res.WarehouseID}] = res
+	}
+
+	results := make([]LineResult, 0, len(lines))
+	for _, l := range lines {
+		res := byKey[key{l.Code, l.WarehouseID}]
+		res.Requested = l.Quantity
+		if res.Status == LineReserved {
+			res.Reserved = l.Quantity
+		}
+		results = append(results, res)
+	}
+
+	return results
+}
+
+//	@Summary		Get a reservation
+//	@Description	Get a reservation with its lines by ID.
+//	@Tags			reservations
+//	@Produce		json
+//	@Param			id	path		int	true	"Reservation ID"
+//	@Success		200	{object}	Reservation
+//	@Failure		400	{object}	route.ErrorResponse
+//	@Failure		404	{object}	route.ErrorResponse
+//	@Failure		500	{object}	route.ErrorResponse
+//	@Router			/reservations/{id} [get]
+//
+// GetReservation возвращает резервирование по ID
+func GetReservation(s Store, id int) (*Reservation, error) {
+	return s.Reservations().Get(id)
+}
+
+//	@Summary		Release a reservation
+//	@Description	Releases an active reservation and returns its units to available stock.
+//	@Tags			reservations
+//	@Produce		json
+//	@Param			id	path		int	true	"Reservation ID"
+//	@Success		200	{object}	Reservation
+//	@Failure		400	{object}	route.ErrorResponse
+//	@Failure		404	{object}	route.ErrorResponse
+//	@Failure		409	{object}	route.ErrorResponse
+//	@Failure		500	{object}	route.ErrorResponse
+//	@Router			/reservations/{id}/release [post]
+//
+// ReleaseReservation снимает резерв и возвращает единицы в доступный остаток
+func ReleaseReservation(s Store, id int) (*Reservation, error) {
+	return finishReservation(s, id, ReservationReleased)
+}
+
+//	@Summary		Fulfill a reservation
+//	@Description	Fulfills an active reservation: reserved units leave the warehouse.
+//	@Tags			reservations
+//	@Produce		json
+//	@Param			id	path		int	true	"Reservation ID"
+//	@Success		200	{object}	Reservation
+//	@Failure		400	{object}	route.ErrorResponse
+//	@Failure		404	{object}	route.ErrorResponse
+//	@Failure		409	{object}	route.ErrorResponse
+//	@Failure		500	{object}	route.ErrorResponse
+//	@Router			/reservations/{id}/fulfill [post]
+//
+// FulfillReservation списывает зарезервированные единицы со склада
+func FulfillReservation(s Store, id int) (*Reservation, error) {
+	return finishReservation(s, id, ReservationFulfilled)
+}
+
+// ExpireReservations снимает активные резервирования с истекшим сроком действия
+// и возвращает количество снятых резервирований
+func ExpireReservations(s Store) (int, error) {
+	ids, err := s.Reservations().ListExpired(time.Now())
+	if err != nil {
+		return 0, err
+	}
+
+	// Каждое резервирование снимается в своей транзакции
+	expired := 0
+	for _, id := range ids {
+		_, err := finishReservation(s, id, ReservationExpired)
+		// Резервирование могли снять или выдать параллельно
+		if errors.Is(err, ErrReservationNotActive) {
+			continue
+		}
+		if err != nil {
+			return expired, err
+		}
+		expired++
+	}
+
+	return expired, nil
+}
+
+// finishReservation переводит активное резервирование в конечный статус
+func finishReservation(s Store, id int, status string) (*Reservation, error) {
+	var r *Reservation
+	var kind string
+	released := 0
+	err := s.InTx(func(tx Repositories) error {
+		// Сначала блокируем продукты резервирования, затем само резервирование
+		current, err := tx.Reservations().Get(id)
+		if err != nil {
+			return err
+		}
+		products := make(map[int]*Product, len(current.Lines))
+		for _, l := range lockOrder(current.Lines) {
+			p, err := tx.Products().GetForUpdate(l.ProductID)
+			// Продукт мог быть удален после резервирования
+			if errors.Is(err, ErrProductNotFound) {
+				continue
+			}
+			if err != nil {
+				return err
+			}
+			products[l.ProductID] = p
+		}
+
+		// Блокируем резервирование, чтобы его нельзя было завершить дважды.
+		// Пока продукты заблокированы, строки могли только уменьшиться
+		r, err = tx.Reservations().GetForUpdate(id)
+		if err != nil {
+			return err
+		}
+		if r.Status != ReservationActive {
+			return ErrReservationNotActive
+		}
+
+		kind = MovementRelease
+		switch status {
+		case ReservationFulfilled:
+			kind = MovementFulfill
+		case ReservationExpired:
+			kind = MovementExpire
+		}
+
+		for _, l := range r.Lines {
+			p := products[l.ProductID]
+			if p == nil {
+				continue
+			}
+
+			// Снимаем не больше, чем резервирование держит и чем зарезервировано у продукта
+			take := l.Quantity
+			if p.ReservedQuantity < take {
+				take = p.ReservedQuantity
+			}
+			p.ReservedQuantity -= take
+			m := StockMovement{ProductID: l.ProductID, WarehouseID: l.WarehouseID, Code: l.Code, Kind: kind, ReservedDelta: -take, ReservationID: &r.ID}
+
+			// При выдаче единицы уходят со склада, в остальных случаях возвращаются в доступный остаток
+			if status == ReservationFulfilled {
+				m.QuantityDelta = -take
+			}
+
+			if err := tx.Products().AddStock(l.ProductID, m.QuantityDelta, m.ReservedDelta); err != nil {
+				return err
+			}
+			if err := tx.Movements().Record(m); err != nil {
+				return err
+			}
+			released += take
+		}
+
+		return tx.Reservations().SetStatus(r, status)
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	// При выдаче единицы уходят со склада, а не возвращаются в доступный остаток
+	if status != ReservationFulfilled {
+		metrics.UnitsReleased.WithLabelValues(kind).Add(float64(released))
+	}
+
+	return r, nil
+}
+
+// lockOrder возвращает строки с различными продуктами в порядке блокировки: по коду, затем по ID продукта.
+// Строки распределяются по складам не в этом порядке, поэтому их нельзя блокировать как есть
+func lockOrder(lines []ReservationLine) []ReservationLine {
+	seen := make(map[int]bool, len(lines))
+	var ordered []ReservationLine
+	for _, l := range lines {
+		if l.ProductID == 0 || seen[l.ProductID] {
+			continue
+		}
+		seen[l.ProductID] = true
+		ordered = append(ordered, l)
+	}
+	sort.Slice(ordered, func(i, j int) bool {
+		if ordered[i].Code != ordered[j].Code {
+			return ordered[i].Code < ordered[j].Code
+		}
+		return ordered[i].ProductID < ordered[j].ProductID
+	})
+
+	return ordered
+}
+
+// lockCodes блокирует строки продуктов по одному запросу на код в порядке кодов,
+// чтобы строки одного кода на разных складах блокировались в порядке ID.
+// Пустой список складов кода блокирует его строки на всех складах
+func lockCodes(tx Repositories, warehouseIDs map[string][]int) error {
+	codes := make([]string, 0, len(warehouseIDs))
+	for code := range warehouseIDs {
+		codes = append(codes, code)
+	}
+	sort.Strings(codes)
+
+	for _, code := range codes {
+		if _, err := tx.Products().LockByCode(code, warehouseIDs[code]...); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// lockStock блокирует строки продукта на всех складах и возвращает остатки на доступных складах
+func lockStock(tx Repositories, code string, warehouseID int) ([]StockLevel, error) {
+	levels, err := tx.Products().LockStock(code, warehouseID)
+	if err != nil {
+		return nil, err
+	}
+	if len(levels) == 0 {
+		return nil, ErrProductNotFound.WithProduct(code)
+	}
+
+	var stock []StockLevel
+	var unavailable error
+	for _, s := range levels {
+		// Недоступные склады пропускаем
+		if !s.Warehouse.IsAvailable {
+			if unavailable == nil {
+				unavailable = ErrWarehouseUnavailable.WithWarehouse(s.Warehouse.ID)
+			}
+			continue
+		}
+		stock = append(stock, s)
+	}
+
+	// Продукт есть только на недоступных складах
+	if len(stock) == 0 {
+		return nil, unavailable
+	}
+
+	return stock, nil
+}
+
+// lineFailure возвращает итог строки для ошибки, при которой частичное резервирование может продолжиться
+func lineFailure(err error) string {
+	switch {
+	case errors.Is(err, ErrProductNotFound):
+		return LineUnknownCode
+	case errors.Is(err, ErrWarehouseUnavailable):
+		return LineUnavailable
+	}
+
+	return ""
+}
+
+// validateLines проверяет, что строки заданы и количество в каждой положительно
+func validateLines(lines []ReservationLine) error {
+	if len(lines) == 0 {
+		return ErrLinesRequired
+	}
+	for _, l := range lines {
+		if l.Quantity <= 0 {
+			return ErrInvalidQuantity.WithProduct(l.Code)
+		}
+	}
+
+	return nil
+}
+
+// mergeLines объединяет строки с одинаковым кодом и складом и сортирует их по коду и складу
+func mergeLines(lines []ReservationLine) []ReservationLine {
+	type key struct {
+		code        string
+		warehouseID int
+	}
+	byKey := make(map[key]int, len(lines))
+	merged := make([]ReservationLine, 0, len(lines))
+	for _, l := range lines {
+		k := key{l.Code, l.WarehouseID}
+		if i, ok := byKey[k]; ok {
+			merged[i].Quantity += l.Quantity
+			continue
+		}
+		byKey[k] = len(merged)
+		merged = append(merged, ReservationLine{Code: l.Code, WarehouseID: l.WarehouseID, Quantity: l.Quantity})
+	}
+
+	sort.Slice(merged, func(i, j int) bool {
+		if merged[i].Code != merged[j].Code {
+			return merged[i].Code < merged[j].Code
+		}
+		return merged[i].WarehouseID < merged[j].WarehouseID
+	})
+
+	return merged
+}
@@ -0,0 +1,713 @@
+package controller_test
+
+import (
+	"errors"
+	"strconv"
+	"sync"
+	"testing"
+	"time"
+
+	"lamoda-test/api/controller"
+	"lamoda-test/pkg/metrics"
+	"lamoda-test/utils"
+
+	"github.com/prometheus/client_golang/prometheus/testutil"
+)
+
+func TestReleaseReservation(t *testing.T) {
+	store := newStore(t)
+
+	// Создаем склад и продукт
+	w := &controller.Warehouse{
+		Name:        utils.RandomString(6),
+		IsAvailable: true,
+	}
+	err := controller.CreateWarehouse(store, w)
+	if err != nil {
+		t.Fatal(err)
+	}
+	p := &controller.Product{
+		Name:        utils.RandomString(6),
+		Size:        utils.RandomSize(),
+		Code:        utils.RandomString(6),
+		Quantity:    3,
+		WarehouseID: w.ID,
+	}
+	err = controller.CreateProduct(store, p)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// Резервируем две единицы одной строкой и одну отдельной с тем же кодом
+	r := &controller.Reservation{
+		OrderRef: utils.RandomString(6),
+		Lines:    []controller.ReservationLine{{Code: p.Code, Quantity: 2}, {Code: p.Code, Quantity: 1}},
+	}
+	err = controller.ReserveProducts(store, r)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if r.ID == 0 || r.Status != controller.ReservationActive {
+		t.Fatalf("Expected active reservation with non-zero ID, got %d %q", r.ID, r.Status)
+	}
+	if len(r.Lines) != 1 || r.Lines[0].Quantity != 3 {
+		t.Fatalf("Expected one merged line with quantity 3, got %+v", r.Lines)
+	}
+
+	// Снимаем резерв
+	released, err := controller.ReleaseReservation(store, r.ID)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if released.Status != controller.ReservationReleased {
+		t.Errorf("Expected status %q, got %q", controller.ReservationReleased, released.Status)
+	}
+
+	page, err := controller.GetRemainingProducts(store, w.ID, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if page.Items[0].Available != 3 {
+		t.Errorf("Expected 3 available units after release, got %d", page.Items[0].Available)
+	}
+
+	// Повторно снять резерв нельзя
+	_, err = controller.ReleaseReservation(store, r.ID)
+	if !errors.Is(err, controller.ErrReservationNotActive) {
+		t.Errorf("Expected ErrReservationNotActive, got %v", err)
+	}
+}
+
+func TestFulfillReservation(t *testing.T) {
+	store := newStore(t)
+
+	// Создаем склад и продукт
+	w := &controller.Warehouse{
+		Name:        utils.RandomString(6),
+		IsAvailable: true,
+	}
+	err := controller.CreateWarehouse(store, w)
+	if err != nil {
+		t.Fatal(err)
+	}
+	p := &controller.Product{
+		Name:        utils.RandomString(6),
+		Size:        utils.RandomSize(),
+		Code:        utils.RandomString(6),
+		Quantity:    3,
+		WarehouseID: w.ID,
+	}
+	err = controller.CreateProduct(store, p)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	r := &controller.Reservation{
+		OrderRef: utils.RandomString(6),
+		Lines:    []controller.ReservationLine{{Code: p.Code, Quantity: 2}},
+	}
+	err = controller.ReserveProducts(store, r)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// Выдаем заказ: единицы уходят со склада вместе с резервом
+	_, err = controller.FulfillReservation(store, r.ID)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	page, err := controller.GetRemainingProducts(store, w.ID, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	got := page.Items[0]
+	if got.Quantity != 1 || got.ReservedQuantity != 0 {
+		t.Errorf("Expected quantity 1 and reserved 0, got %d and %d", got.Quantity, got.ReservedQuantity)
+	}
+
+	// Несуществующее резервирование
+	_, err = controller.GetReservation(store, -1)
+	if !errors.Is(err, controller.ErrReservationNotFound) {
+		t.Errorf("Expected ErrReservationNotFound, got %v", err)
+	}
+}
+
+func TestExpireReservations(t *testing.T) {
+	store := newStore(t)
+
+	// Создаем склад и продукт
+	w := &controller.Warehouse{
+		Name:        utils.RandomString(6),
+		IsAvailable: true,
+	}
+	err := controller.CreateWarehouse(store, w)
+	if err != nil {
+		t.Fatal(err)
+	}
+	p := &controller.Product{
+		Name:        utils.RandomString(6),
+		Size:        utils.RandomSize(),
+		Code:        utils.RandomString(6),
+		Quantity:    1,
+		WarehouseID: w.ID,
+	}
+	err = controller.CreateProduct(store, p)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// Резервирование, срок действия которого уже истек
+	expiresAt := time.Now().Add(-time.Minute)
+	r := &controller.Reservation{
+		OrderRef:  utils.RandomString(6),
+		Lines:     []controller.ReservationLine{{Code: p.Code, Quantity: 1}},
+		ExpiresAt: &expiresAt,
+	}
+	err = controller.ReserveProducts(store, r)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	expired, err := controller.ExpireReservations(store)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if expired < 1 {
+		t.Errorf("Expected at least 1 expired reservation, got %d", expired)
+	}
+
+	got, err := controller.GetReservation(store, r.ID)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got.Status != controller.ReservationExpired {
+		t.Errorf("Expected status %q, got %q", controller.ReservationExpired, got.Status)
+	}
+
+	// Единица вернулась в доступный остаток
+	page, err := controller.GetRemainingProducts(store, w.ID, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if page.Items[0].Available != 1 {
+		t.Errorf("Expected 1 available unit after expiry, got %d", page.Items[0].Available)
+	}
+}
+
+func TestReserveProductsUnavailableWarehouse(t *testing.T) {
+	store := newStore(t)
+
+	// Создаем закрытый склад с продуктом
+	w := &controller.Warehouse{
+		Name:        utils.RandomString(6),
+		IsAvailable: false,
+	}
+	err := controller.CreateWarehouse(store, w)
+	if err != nil {
+		t.Fatal(err)
+	}
+	p := &controller.Product{
+		Name:        utils.RandomString(6),
+		Size:        utils.RandomSize(),
+		Code:        utils.RandomString(6),
+		Quantity:    1,
+		WarehouseID: w.ID,
+	}
+	err = controller.CreateProduct(store, p)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// Резервирование без склада и с явно указанным складом отклоняется
+	for _, warehouseID := range []int{0, w.ID} {
+		err = controller.ReserveProducts(store, &controller.Reservation{
+			OrderRef:    utils.RandomString(6),
+			WarehouseID: warehouseID,
+			Lines:       []controller.ReservationLine{{Code: p.Code, Quantity: 1}},
+		})
+		var unavailable *controller.Error
+		if !errors.Is(err, controller.ErrWarehouseUnavailable) || !errors.As(err, &unavailable) {
+			t.Fatalf("Expected ErrWarehouseUnavailable, got %v", err)
+		}
+		if unavailable.WarehouseID != w.ID {
+			t.Errorf("Expected warehouse %d in error, got %d", w.ID, unavailable.WarehouseID)
+		}
+	}
+}
+
+func TestReserveProductsAcrossWarehouses(t *testing.T) {
+	store := newStore(t)
+
+	// Один и тот же код на двух доступных складах и на одном закрытом
+	code := utils.RandomString(6)
+	var warehouses []*controller.Warehouse
+	for i, available := range []bool{true, true, false} {
+		w := &controller.Warehouse{
+			Name:        utils.RandomString(6),
+			IsAvailable: available,
+		}
+		err := controller.CreateWarehouse(store, w)
+		if err != nil {
+			t.Fatal(err)
+		}
+		err = controller.CreateProduct(store, &controller.Product{
+			Name:        utils.RandomString(6),
+			Size:        utils.RandomSize(),
+			Code:        code,
+			Quantity:    2 + i,
+			WarehouseID: w.ID,
+		})
+		if err != nil {
+			t.Fatal(err)
+		}
+		warehouses = append(warehouses, w)
+	}
+
+	// 5 единиц есть только с учетом обоих доступных складов
+	r := &controller.Reservation{
+		OrderRef: utils.RandomString(6),
+		Lines:    []controller.ReservationLine{{Code: code, Quantity: 5}},
+		Strategy: controller.FewestSplits{},
+	}
+	err := controller.ReserveProducts(store, r)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(r.Lines) != 2 {
+		t.Fatalf("Expected 2 lines, got %+v", r.Lines)
+	}
+	if r.Lines[0].WarehouseID != warehouses[1].ID || r.Lines[0].Quantity != 3 {
+		t.Errorf("Expected 3 units from warehouse %d first, got %+v", warehouses[1].ID, r.Lines[0])
+	}
+
+	// Закрытый склад пропускается, поэтому шестой единицы нет
+	err = controller.ReserveProducts(store, &controller.Reservation{
+		OrderRef: utils.RandomString(6),
+		Lines:    []controller.ReservationLine{{Code: code, Quantity: 1}},
+	})
+	if err == nil || err.Error() != "product is out of stock" {
+		t.Errorf("Expected 'product is out of stock', got %v", err)
+	}
+}
+
+func TestReserveProductsUnknownCode(t *testing.T) {
+	store := newStore(t)
+
+	// Неизвестный код возвращает ошибку предметной области с кодом продукта, а не sql.ErrNoRows
+	code := utils.RandomString(8)
+	err := controller.ReserveProducts(store, &controller.Reservation{
+		OrderRef: utils.RandomString(6),
+		Lines:    []controller.ReservationLine{{Code: code, Quantity: 1}},
+	})
+	var e *controller.Error
+	if !errors.As(err, &e) || !errors.Is(err, controller.ErrProductNotFound) || e.ProductCode != code {
+		t.Errorf("Expected ErrProductNotFound for %q, got %v", code, err)
+	}
+}
+
+func TestReserveProductsPartial(t *testing.T) {
+	store := newStore(t)
+
+	// Создаем склад и два продукта
+	w := &controller.Warehouse{Name: utils.RandomString(6), IsAvailable: true}
+	err := controller.CreateWarehouse(store, w)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var codes []string
+	for _, quantity := range []int{5, 1} {
+		p := &controller.Product{Name: utils.RandomString(6), Size: utils.RandomSize(), Code: utils.RandomString(8), Quantity: quantity, WarehouseID: w.ID}
+		err = controller.CreateProduct(store, p)
+		if err != nil {
+			t.Fatal(err)
+		}
+		codes = append(codes, p.Code)
+	}
+	unknown := utils.RandomString(10)
+
+	// Первая строка резервируется, второй не хватает остатка, третьего кода нет,
+	// четвертая резервируется вместе с первой
+	r := &controller.Reservation{
+		OrderRef: utils.RandomString(6),
+		Lines: []controller.ReservationLine{
+			{Code: codes[0], Quantity: 2},
+			{Code: codes[1], Quantity: 3},
+			{Code: unknown, Quantity: 1},
+			{Code: codes[0], Quantity: 1},
+		},
+		Partial: true,
+	}
+	err = controller.ReserveProducts(store, r)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(r.Lines) != 1 || r.Lines[0].Code != codes[0] {
+		t.Errorf("Expected only %s to be reserved, got %+v", codes[0], r.Lines)
+	}
+	// Итоги идут в порядке строк запроса, по одному на строку
+	want := []controller.LineResult{
+		{Code: codes[0], Requested: 2, Reserved: 2, Available: 5, Status: controller.LineReserved},
+		{Code: codes[1], Requested: 3, Available: 1, Status: controller.LineInsufficient},
+		{Code: unknown, Requested: 1, Status: controller.LineUnknownCode},
+		{Code: codes[0], Requested: 1, Reserved: 1, Available: 5, Status: controller.LineReserved},
+	}
+	if len(r.Results) != len(want) {
+		t.Fatalf("Expected %d results, got %+v", len(want), r.Results)
+	}
+	for i, res := range r.Results {
+		if res != want[i] {
+			t.Errorf("Expected result %d to be %+v, got %+v", i, want[i], res)
+		}
+	}
+
+	// Если не хватает ничего, резервирование не создается
+	r = &controller.Reservation{
+		OrderRef: utils.RandomString(6),
+		Lines:    []controller.ReservationLine{{Code: unknown, Quantity: 1}},
+		Partial:  true,
+	}
+	err = controller.ReserveProducts(store, r)
+	if !errors.Is(err, controller.ErrNothingReserved) || r.ID != 0 || len(r.Results) != 1 {
+		t.Errorf("Expected ErrNothingReserved with one result, got %v and %+v", err, r)
+	}
+}
+
+func TestReserveProductsConcurrent(t *testing.T) {
+	store := newStore(t)
+
+	w := &controller.Warehouse{
+		Name:        utils.RandomString(6),
+		IsAvailable: true,
+	}
+	err := controller.CreateWarehouse(store, w)
+	if err != nil {
+		t.Fatal(err)
+	}
+	p := &controller.Product{
+		Name:        utils.RandomString(6),
+		Size:        utils.RandomSize(),
+		Code:        utils.RandomString(6),
+		Quantity:    5,
+		WarehouseID: w.ID,
+	}
+	err = controller.CreateProduct(store, p)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// Параллельные заказы не могут зарезервировать больше физического остатка
+	const orders = 20
+	errs := make(chan error, orders)
+	var wg sync.WaitGroup
+	for i := 0; i < orders; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			errs <- controller.ReserveProducts(store, &controller.Reservation{
+				OrderRef: utils.RandomString(6),
+				Lines:    []controller.ReservationLine{{Code: p.Code, Quantity: 1}},
+			})
+		}()
+	}
+	wg.Wait()
+	close(errs)
+
+	reserved := 0
+	for err := range errs {
+		switch {
+		case err == nil:
+			reserved++
+		case !errors.Is(err, controller.ErrOutOfStock):
+			t.Errorf("Expected ErrOutOfStock, got %v", err)
+		}
+	}
+	if reserved != p.Quantity {
+		t.Errorf("Expected %d reservations, got %d", p.Quantity, reserved)
+	}
+
+	got, err := controller.GetProduct(store, p.ID)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got.ReservedQuantity != p.Quantity || got.Available != 0 {
+		t.Errorf("Expected all %d units reserved, got %+v", p.Quantity, got)
+	}
+}
+
+func TestReleaseReservationConcurrentWithReserve(t *testing.T) {
+	store := newStore(t)
+
+	// Код на двух складах: крупнейший остаток на складе с большим ID,
+	// поэтому строки резервирования идут не в порядке ID продуктов
+	code := utils.RandomString(6)
+	for _, quantity := range []int{10, 100} {
+		w := &controller.Warehouse{
+			Name:        utils.RandomString(6),
+			IsAvailable: true,
+		}
+		err := controller.CreateWarehouse(store, w)
+		if err != nil {
+			t.Fatal(err)
+		}
+		err = controller.CreateProduct(store, &controller.Product{
+			Name:        utils.RandomString(6),
+			Size:        utils.RandomSize(),
+			Code:        code,
+			Quantity:    quantity,
+			WarehouseID: w.ID,
+		})
+		if err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	// Снятие резерва и параллельное резервирование того же кода не должны взаимоблокироваться
+	for i := 0; i < 20; i++ {
+		r := &controller.Reservation{
+			OrderRef: utils.RandomString(6),
+			Lines:    []controller.ReservationLine{{Code: code, Quantity: 105}},
+			Strategy: controller.FewestSplits{},
+		}
+		err := controller.ReserveProducts(store, r)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if len(r.Lines) != 2 || r.Lines[0].ProductID < r.Lines[1].ProductID {
+			t.Fatalf("Expected lines in reverse product order, got %+v", r.Lines)
+		}
+
+		other := &controller.Reservation{
+			OrderRef: utils.RandomString(6),
+			Lines:    []controller.ReservationLine{{Code: code, Quantity: 1}},
+		}
+		var wg sync.WaitGroup
+		var releaseErr, reserveErr error
+		wg.Add(2)
+		go func() {
+			defer wg.Done()
+			_, releaseErr = controller.ReleaseReservation(store, r.ID)
+		}()
+		go func() {
+			defer wg.Done()
+			reserveErr = controller.ReserveProducts(store, other)
+		}()
+		wg.Wait()
+		if releaseErr != nil {
+			t.Fatalf("Expected release to succeed, got %v", releaseErr)
+		}
+		if reserveErr != nil {
+			t.Fatalf("Expected reserve to succeed, got %v", reserveErr)
+		}
+
+		_, err = controller.ReleaseReservation(store, other.ID)
+		if err != nil {
+			t.Fatal(err)
+		}
+	}
+}
+
+func TestReserveProductsMetrics(t *testing.T) {
+	store := newStore(t)
+
+	w := &controller.Warehouse{
+		Name:        utils.RandomString(6),
+		IsAvailable: true,
+	}
+	err := controller.CreateWarehouse(store, w)
+	if err != nil {
+		t.Fatal(err)
+	}
+	p := &controller.Product{
+		Name:        utils.RandomString(6),
+		Size:        utils.RandomSize(),
+		Code:        utils.RandomString(6),
+		Quantity:    3,
+		WarehouseID: w.ID,
+	}
+	err = controller.CreateProduct(store, p)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// Счетчики общие для всех тестов пакета, поэтому сравниваем приращения
+	created := testutil.ToFloat64(metrics.ReservationsCreated)
+	failed := testutil.ToFloat64(metrics.ReservationsFailed.WithLabelValues("out_of_stock"))
+	reserved := testutil.ToFloat64(metrics.UnitsReserved)
+	released := testutil.ToFloat64(metrics.UnitsReleased.WithLabelValues(controller.MovementRelease))
+	outOfStock := testutil.ToFloat64(metrics.OutOfStock.WithLabelValues(strconv.Itoa(w.ID)))
+
+	r := &controller.Reservation{
+		OrderRef: utils.RandomString(6),
+		Lines:    []controller.ReservationLine{{Code: p.Code, Quantity: 2}},
+	}
+	err = controller.ReserveProducts(store, r)
+	if err != nil {
+		t.Fatal(err)
+	}
+	err = controller.ReserveProducts(store, &controller.Reservation{
+		OrderRef: utils.RandomString(6),
+		Lines:    []controller.ReservationLine{{Code: p.Code, Quantity: 2}},
+	})
+	if !errors.Is(err, controller.ErrOutOfStock) {
+		t.Fatalf("Expected ErrOutOfStock, got %v", err)
+	}
+	_, err = controller.ReleaseReservation(store, r.ID)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if got := testutil.ToFloat64(metrics.ReservationsCreated) - created; got != 1 {
+		t.Errorf("Expected 1 created reservation, got %v", got)
+	}
+	if got := testutil.ToFloat64(metrics.ReservationsFailed.WithLabelValues("out_of_stock")) - failed; got != 1 {
+		t.Errorf("Expected 1 failed reservation, got %v", got)
+	}
+	if got := testutil.ToFloat64(metrics.UnitsReserved) - reserved; got != 2 {
+		t.Errorf("Expected 2 reserved units, got %v", got)
+	}
+	if got := testutil.ToFloat64(metrics.UnitsReleased.WithLabelValues(controller.MovementRelease)) - released; got != 2 {
+		t.Errorf("Expected 2 released units, got %v", got)
+	}
+	if got := testutil.ToFloat64(metrics.OutOfStock.WithLabelValues(strconv.Itoa(w.ID))) - outOfStock; got != 1 {
+		t.Errorf("Expected 1 out of stock event, got %v", got)
+	}
+}
+
+func TestReleaseProductsByCodeReleasesReservation(t *testing.T) {
+	store := newStore(t)
+
+	// Создаем склад и продукт
+	w := &controller.Warehouse{
+		Name:        utils.RandomString(6),
+		IsAvailable: true,
+	}
+	err := controller.CreateWarehouse(store, w)
+	if err != nil {
+		t.Fatal(err)
+	}
+	p := &controller.Product{
+		Name:        utils.RandomString(6),
+		Size:        utils.RandomSize(),
+		Code:        utils.RandomString(6),
+		Quantity:    3,
+		WarehouseID: w.ID,
+	}
+	err = controller.CreateProduct(store, p)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// Резервируем все единицы и снимаем резерв по коду
+	a := &controller.Reservation{OrderRef: utils.RandomString(6), Lines: []controller.ReservationLine{{Code: p.Code, Quantity: 3}}}
+	err = controller.ReserveProducts(store, a)
+	if err != nil {
+		t.Fatal(err)
+	}
+	err = controller.ReleaseProducts(store, []controller.ReservationLine{{Code: p.Code, Quantity: 3}})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// Резервирование без строк снято и больше не держит единицы
+	got, err := controller.GetReservation(store, a.ID)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got.Status != controller.ReservationReleased || len(got.Lines) != 0 {
+		t.Errorf("Expected released reservation without lines, got %q with %+v", got.Status, got.Lines)
+	}
+	_, err = controller.FulfillReservation(store, a.ID)
+	if !errors.Is(err, controller.ErrReservationNotActive) {
+		t.Errorf("Expected ErrReservationNotActive, got %v", err)
+	}
+
+	// Освободившиеся единицы резервирует другой заказ и получает их при выдаче
+	b := &controller.Reservation{OrderRef: utils.RandomString(6), Lines: []controller.ReservationLine{{Code: p.Code, Quantity: 3}}}
+	err = controller.ReserveProducts(store, b)
+	if err != nil {
+		t.Fatal(err)
+	}
+	_, err = controller.FulfillReservation(store, b.ID)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	page, err := controller.GetRemainingProducts(store, w.ID, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if page.Items[0].Quantity != 0 || page.Items[0].ReservedQuantity != 0 {
+		t.Errorf("Expected quantity 0 and reserved 0, got %d and %d", page.Items[0].Quantity, page.Items[0].ReservedQuantity)
+	}
+}
+
+func TestReleaseProductsByCodePartially(t *testing.T) {
+	store := newStore(t)
+
+	// Создаем склад и продукт
+	w := &controller.Warehouse{
+		Name:        utils.RandomString(6),
+		IsAvailable: true,
+	}
+	err := controller.CreateWarehouse(store, w)
+	if err != nil {
+		t.Fatal(err)
+	}
+	p := &controller.Product{
+		Name:        utils.RandomString(6),
+		Size:        utils.RandomSize(),
+		Code:        utils.RandomString(6),
+		Quantity:    5,
+		WarehouseID: w.ID,
+	}
+	err = controller.CreateProduct(store, p)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// Два заказа держат по две единицы, по коду снимаем три
+	a := &controller.Reservation{OrderRef: utils.RandomString(6), Lines: []controller.ReservationLine{{Code: p.Code, Quantity: 2}}}
+	err = controller.ReserveProducts(store, a)
+	if err != nil {
+		t.Fatal(err)
+	}
+	b := &controller.Reservation{OrderRef: utils.RandomString(6), Lines: []controller.ReservationLine{{Code: p.Code, Quantity: 2}}}
+	err = controller.ReserveProducts(store, b)
+	if err != nil {
+		t.Fatal(err)
+	}
+	err = controller.ReleaseProducts(store, []controller.ReservationLine{{Code: p.Code, Quantity: 3}})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// Новое резервирование снято целиком, у старого осталась одна единица
+	got, err := controller.GetReservation(store, b.ID)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got.Status != controller.ReservationReleased {
+		t.Errorf("Expected newer reservation to be released, got %q", got.Status)
+	}
+	got, err = controller.GetReservation(store, a.ID)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got.Status != controller.ReservationActive || len(got.Lines) != 1 || got.Lines[0].Quantity != 1 {
+		t.Fatalf("Expected active reservation with one unit, got %q with %+v", got.Status, got.Lines)
+	}
+
+	// Выдача списывает только оставшуюся единицу
+	_, err = controller.FulfillReservation(store, a.ID)
+	if err != nil {
+		t.Fatal(err)
+	}
+	page, err := controller.GetRemainingProducts(store, w.ID, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if page.Items[0].Quantity != 4 || page.Items[0].ReservedQuantity != 0 {
+		t.Errorf("Expected quantity 4 and reserved 0, got %d and %d", page.Items[0].Quantity, page.Items[0].ReservedQuantity)
+	}
+}
@@ -0,0 +1,95 @@
+package controller_test
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"os"
+	"sync"
+	"testing"
+	"time"
+
+	"lamoda-test/api/controller"
+	"lamoda-test/migrations"
+	"lamoda-test/pkg/client/memory"
+	"lamoda-test/pkg/client/postgresql"
+
+	_ "github.com/lib/pq"
+)
+
+// testStorage хранилище, на котором выполняются тесты: memory по умолчанию или postgres.
+// Для postgres подключение берется из тех же переменных POSTGRES_*, что и в конфигурации приложения
+var testStorage = os.Getenv("TEST_STORAGE")
+
+// testTables таблицы, которые очищаются перед каждым тестом на postgres
+const testTables = "warehouse, products, reservations, reservation_lines, stock_movements, transfers, receipts, receipt_lines, adjustments, idempotency_keys"
+
+var (
+	pgOnce sync.Once
+	pgDB   *sql.DB
+	pgErr  error
+)
+
+// newStore возвращает пустое хранилище для теста.
+// Если тесты запущены на postgres, а база недоступна, тест пропускается
+func newStore(t *testing.T) controller.Store {
+	t.Helper()
+
+	if testStorage != "postgres" {
+		return memory.NewStore()
+	}
+
+	pgOnce.Do(func() {
+		pgDB, pgErr = openTestDB()
+	})
+	if pgErr != nil {
+		t.Skipf("postgres is not available: %v", pgErr)
+	}
+
+	_, err := pgDB.Exec("TRUNCATE " + testTables + " RESTART IDENTITY CASCADE")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	return postgresql.NewStore(pgDB)
+}
+
+// openTestDB подключается к базе без повторных попыток и применяет миграции
+func openTestDB() (*sql.DB, error) {
+	dsn := fmt.Sprintf("postgres://%s:%s@%s:%s/%s?sslmode=disable",
+		os.Getenv("POSTGRES_USER"), os.Getenv("POSTGRES_PASS"),
+		envOr("POSTGRES_HOST", "localhost"), envOr("POSTGRES_PORT", "5432"), os.Getenv("POSTGRES_NAME"))
+	db, err := sql.Open("postgres", dsn)
+	if err != nil {
+		return nil, err
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+	if err := db.PingContext(ctx); err != nil {
+		db.Close()
+		return nil, err
+	}
+
+	m, err := postgresql.NewMigrator(ctx, db, migrations.FS)
+	if err != nil {
+		db.Close()
+		return nil, err
+	}
+	defer m.Close()
+	if _, _, err := m.Up(); err != nil {
+		db.Close()
+		return nil, err
+	}
+
+	return db, nil
+}
+
+// envOr возвращает значение переменной окружения или def, если она не задана
+func envOr(key, def string) string {
+	if v := os.Getenv(key); v != "" {
+		return v
+	}
+
+	return def
+}
@@ -0,0 +1,132 @@
+package controller
+
+import "time"
+
+// ErrSameWarehouse возвращается при попытке переместить продукт на тот же склад
+var ErrSameWarehouse = &Error{Kind: KindValidation, Code: "same_warehouse", Message: "source and destination warehouses must differ"}
+
+// Transfer перемещение продукта между складами
+type Transfer struct {
+	ID              int       `json:"id"`
+	Code            string    `json:"code"`
+	Quantity        int       `json:"quantity"`
+	FromWarehouseID int       `json:"from_warehouse_id"`
+	ToWarehouseID   int       `json:"to_warehouse_id"`
+	CreatedAt       time.Time `json:"created_at"`
+}
+
+//	@Summary		Transfer stock between warehouses
+//	@Description	Atomically moves available units of a product from one warehouse to another.
+//	@Description	The product row is created at the destination if needed. The destination must be available.
+//	@Tags			transfers
+//	@Accept			json
+//	@Produce		json
+//	@Param			transfer	body		Transfer	true	"Product code, quantity and warehouses"
+//	@Success		201			{object}	Transfer
+//	@Failure		400			{object}	route.ErrorResponse	"Invalid request format"
+//	@Failure		404			{object}	route.ErrorResponse	"Warehouse or product not found"
+//	@Failure		409			{object}	route.ErrorResponse	"Out of stock or destination unavailable"
+//	@Failure		422			{object}	route.ErrorResponse	"Invalid quantity or same warehouse"
+//	@Failure		500			{object}	route.ErrorResponse	"Internal server error"
+//	@Router			/transfers [post]
+//
+// CreateTransfer перемещает доступные единицы продукта между складами
+func CreateTransfer(s Store, t *Transfer) error {
+	if t.Quantity <= 0 {
+		return ErrInvalidQuantity.WithProduct(t.Code)
+	}
+	if t.FromWarehouseID == t.ToWarehouseID {
+		return ErrSameWarehouse
+	}
+
+	ids := []int{t.FromWarehouseID, t.ToWarehouseID}
+	if ids[0] > ids[1] {
+		ids[0], ids[1] = ids[1], ids[0]
+	}
+
+	return s.InTx(func(tx Repositories) error {
+		// Блокируем оба склада на чтение
+		warehouses := map[int]*Warehouse{}
+		for _, id := range ids {
+			w, err := tx.Warehouses().GetForShare(id)
+			if err != nil {
+				return err
+			}
+			warehouses[id] = w
+		}
+
+		// Принимать продукты может только работающий склад
+		if to := warehouses[t.ToWarehouseID]; !to.IsAvailable {
+			return ErrWarehouseUnavailable.WithWarehouse(to.ID)
+		}
+
+		products, err := tx.Products().ListByCode(t.Code)
+		if err != nil {
+			return err
+		}
+		var source *Product
+		exists := false
+		for i := range products {
+			switch products[i].WarehouseID {
+			case t.FromWarehouseID:
+				source = &products[i]
+			case t.ToWarehouseID:
+				exists = true
+			}
+		}
+		if source == nil {
+			return ErrProductNotFound.WithProduct(t.Code)
+		}
+
+		// Создаем продукт на складе назначения, если его там еще нет.
+		// Новый продукт проверяется так же, как при создании
+		target := Product{Name: source.Name, Size: source.Size, Code: t.Code, WarehouseID: t.ToWarehouseID}
+		if !exists {
+			if err := validateStruct(&target); err != nil {
+				return err
+			}
+		}
+		err = tx.Products().CreateIfMissing(&target)
+		if err != nil {
+			return err
+		}
+
+		// Блокируем обе строки продукта
+		products, err = tx.Products().LockByCode(t.Code, t.FromWarehouseID, t.ToWarehouseID)
+		if err != nil {
+			return err
+		}
+		var from, to Product
+		for _, p := range products {
+			if p.WarehouseID == t.FromWarehouseID {
+				from = p
+			} else {
+				to = p
+			}
+		}
+
+		// Перемещать можно только незарезервированные единицы
+		if from.Available < t.Quantity {
+			return ErrOutOfStock.WithProduct(t.Code)
+		}
+
+		if err := tx.Transfers().Create(t); err != nil {
+			return err
+		}
+
+		movements := []StockMovement{
+			{ProductID: from.ID, WarehouseID: from.WarehouseID, Code: t.Code, Kind: MovementTransferOut, QuantityDelta: -t.Quantity, TransferID: &t.ID},
+			{ProductID: to.ID, WarehouseID: to.WarehouseID, Code: t.Code, Kind: MovementTransferIn, QuantityDelta: t.Quantity, TransferID: &t.ID},
+		}
+		for _, m := range movements {
+			if err := tx.Products().AddStock(m.ProductID, m.QuantityDelta, 0); err != nil {
+				return err
+			}
+			if err := tx.Movements().Record(m); err != nil {
+				return err
+			}
+		}
+
+		return nil
+	})
+}
@@ -0,0 +1,98 @@
+package controller_test
+
+import (
+	"errors"
+	"testing"
+
+	"lamoda-test/api/controller"
+	"lamoda-test/utils"
+)
+
+func TestCreateTransfer(t *testing.T) {
+	store := newStore(t)
+
+	// Создаем два склада и продукт на первом
+	from := &controller.Warehouse{Name: utils.RandomString(6), IsAvailable: true}
+	err := controller.CreateWarehouse(store, from)
+	if err != nil {
+		t.Fatal(err)
+	}
+	to := &controller.Warehouse{Name: utils.RandomString(6), IsAvailable: true}
+	err = controller.CreateWarehouse(store, to)
+	if err != nil {
+		t.Fatal(err)
+	}
+	p := &controller.Product{
+		Name:        utils.RandomString(6),
+		Size:        utils.RandomSize(),
+		Code:        utils.RandomString(6),
+		Quantity:    5,
+		WarehouseID: from.ID,
+	}
+	err = controller.CreateProduct(store, p)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// Перемещаем три единицы, на складе назначения создается продукт
+	tr := &controller.Transfer{Code: p.Code, Quantity: 3, FromWarehouseID: from.ID, ToWarehouseID: to.ID}
+	err = controller.CreateTransfer(store, tr)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	products, err := controller.GetProductsByCode(store, p.Code)
+	if err != nil {
+		t.Fatal(err)
+	}
+	quantities := map[int]int{}
+	for _, product := range products {
+		quantities[product.WarehouseID] = product.Quantity
+	}
+	if quantities[from.ID] != 2 || quantities[to.ID] != 3 {
+		t.Errorf("Expected quantities 2 and 3 after transfer, got %v", quantities)
+	}
+
+	// Остатка на складе-источнике не хватает
+	err = controller.CreateTransfer(store, &controller.Transfer{Code: p.Code, Quantity: 3, FromWarehouseID: from.ID, ToWarehouseID: to.ID})
+	if !errors.Is(err, controller.ErrOutOfStock) {
+		t.Errorf("Expected ErrOutOfStock, got %v", err)
+	}
+
+	// Недоступный склад не принимает продукты
+	_, err = controller.UpdateWarehouse(store, from.ID, &controller.WarehousePatch{IsAvailable: new(bool)})
+	if err != nil {
+		t.Fatal(err)
+	}
+	err = controller.CreateTransfer(store, &controller.Transfer{Code: p.Code, Quantity: 1, FromWarehouseID: to.ID, ToWarehouseID: from.ID})
+	if !errors.Is(err, controller.ErrWarehouseUnavailable) {
+		t.Errorf("Expected ErrWarehouseUnavailable, got %v", err)
+	}
+}
+
+func TestCreateTransferValidatesNewProduct(t *testing.T) {
+	store := newStore(t)
+
+	from := &controller.Warehouse{Name: utils.RandomString(6), IsAvailable: true}
+	err := controller.CreateWarehouse(store, from)
+	if err != nil {
+		t.Fatal(err)
+	}
+	to := &controller.Warehouse{Name: utils.RandomString(6), IsAvailable: true}
+	err = controller.CreateWarehouse(store, to)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// Продукт без названия сохранен до появления проверок и не может появиться на новом складе
+	p := &controller.Product{Code: utils.RandomString(6), Quantity: 5, WarehouseID: from.ID}
+	err = store.Products().Create(p)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	err = controller.CreateTransfer(store, &controller.Transfer{Code: p.Code, Quantity: 1, FromWarehouseID: from.ID, ToWarehouseID: to.ID})
+	if !errors.Is(err, controller.ErrValidation) {
+		t.Errorf("Expected ErrValidation, got %v", err)
+	}
+}
@@ -0,0 +1,104 @@
+package controller
+
+import (
+	"errors"
+	"fmt"
+	"reflect"
+	"regexp"
+	"strings"
+
+	"github.com/go-playground/validator/v10"
+)
+
+var (
+	// productCodePattern код продукта: латиница, цифры, дефис и подчеркивание, до 64 символов
+	productCodePattern = regexp.MustCompile(`^[A-Za-z0-9][A-Za-z0-9_-]{0,63}$`)
+	// numericSizePattern числовой размер или диапазон размеров: 42, 42.5, 42-44
+	numericSizePattern = regexp.MustCompile(`^\d{1,3}(\.\d)?(-\d{1,3}(\.\d)?)?$`)
+)
+
+// letterSizes буквенные размеры
+var letterSizes = map[string]bool{
+	"XXS": true, "XS": true, "S": true, "M": true, "L": true, "XL": true, "XXL": true, "XXXL": true,
+}
+
+// ErrValidation возвращается, если запрос не прошел проверку, список полей - в Fields
+var ErrValidation = &Error{Kind: KindValidation, Code: "validation_failed", Message: "request validation failed"}
+
+// FieldError ошибка проверки одного поля запроса
+type FieldError struct {
+	Field   string `json:"field"`
+	Rule    string `json:"rule"`
+	Message string `json:"message"`
+}
+
+var validate = newValidator()
+
+// newValidator создает валидатор с правилами для кодов и размеров продуктов
+func newValidator() *validator.Validate {
+	v := validator.New()
+
+	// В ошибках поля называются так же, как в JSON
+	v.RegisterTagNameFunc(func(f reflect.StructField) string {
+		name := strings.SplitN(f.Tag.Get("json"), ",", 2)[0]
+		if name == "-" {
+			return ""
+		}
+		return name
+	})
+
+	v.RegisterValidation("product_code", func(fl validator.FieldLevel) bool {
+		return productCodePattern.MatchString(fl.Field().String())
+	})
+	// Пустой размер означает, что размер не задан
+	v.RegisterValidation("product_size", func(fl validator.FieldLevel) bool {
+		size := fl.Field().String()
+		return size == "" || letterSizes[size] || numericSizePattern.MatchString(size)
+	})
+
+	return v
+}
+
+// validateStruct проверяет структуру по тегам validate и возвращает ErrValidation со списком полей
+func validateStruct(s interface{}) error {
+	err := validate.Struct(s)
+	var invalid validator.ValidationErrors
+	if !errors.As(err, &invalid) {
+		return err
+	}
+
+	e := *ErrValidation
+	for _, fe := range invalid {
+		e.Fields = append(e.Fields, FieldError{Field: fe.Field(), Rule: fe.Tag(), Message: fieldMessage(fe)})
+	}
+
+	return &e
+}
+
+// fieldMessage описывает нарушенное правило для клиента
+func fieldMessage(fe validator.FieldError) string {
+	switch fe.Tag() {
+	case "required":
+		return "is required"
+	case "required_with":
+		return fmt.Sprintf("is required with %s", strings.ToLower(fe.Param()))
+	case "min":
+		if fe.Kind() == reflect.String {
+			return fmt.Sprintf("must be at least %s characters long", fe.Param())
+		}
+		return fmt.Sprintf("must be at least %s", fe.Param())
+	case "max":
+		if fe.Kind() == reflect.String {
+			return fmt.Sprintf("must be at most %s characters long", fe.Param())
+		}
+		return fmt.Sprintf("must be at most %s", fe.Param())
+	case "gt":
+		return fmt.Sprintf("must be greater than %s", fe.Param())
+	case "product_code":
+		return "must be up to 64 latin letters, digits, '-' or '_'"
+	case "product_size":
+		return "must be one of XXS, XS, S, M, L, XL, XXL, XXXL or a numeric size like 42, 42.5 or 42-44"
+	}
+
+	return "is invalid"
+}
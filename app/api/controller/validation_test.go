@@ -0,0 +1,105 @@
+package controller
+
+import (
+	"errors"
+	"testing"
+)
+
+func TestValidateProduct(t *testing.T) {
+	valid := Product{Name: "Футболка", Size: "M", Code: "TSHIRT-01", Quantity: 0, WarehouseID: 1}
+
+	tests := []struct {
+		name       string
+		modify     func(p *Product)
+		wantFields []string
+	}{
+		{"valid", func(p *Product) {}, nil},
+		{"numeric size", func(p *Product) { p.Size = "42.5" }, nil},
+		{"size range", func(p *Product) { p.Size = "44-46" }, nil},
+		{"no size", func(p *Product) { p.Size = "" }, nil},
+		{"empty name", func(p *Product) { p.Name = "" }, []string{"name"}},
+		{"negative quantity", func(p *Product) { p.Quantity = -1 }, []string{"quantity"}},
+		{"bad code", func(p *Product) { p.Code = "код товара" }, []string{"code"}},
+		{"unknown size", func(p *Product) { p.Size = "huge" }, []string{"size"}},
+		{"several fields", func(p *Product) { p.Code = ""; p.WarehouseID = 0 }, []string{"code", "warehouse_id"}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			p := valid
+			tt.modify(&p)
+			checkFields(t, validateStruct(&p), tt.wantFields)
+		})
+	}
+}
+
+func TestValidateProductPatch(t *testing.T) {
+	name, empty, size, badSize := "Футболка", "", "", "huge"
+	quantity, negative := 0, -1
+
+	tests := []struct {
+		name       string
+		patch      ProductPatch
+		wantFields []string
+	}{
+		{"nothing", ProductPatch{}, nil},
+		{"valid", ProductPatch{Name: &name, Size: &size, Quantity: &quantity}, nil},
+		{"empty name", ProductPatch{Name: &empty}, []string{"name"}},
+		{"unknown size", ProductPatch{Size: &badSize}, []string{"size"}},
+		{"negative quantity", ProductPatch{Quantity: &negative}, []string{"quantity"}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			checkFields(t, validateStruct(&tt.patch), tt.wantFields)
+		})
+	}
+}
+
+func TestValidateWarehouse(t *testing.T) {
+	lat, lon, far := 55.75, 37.62, 200.0
+
+	tests := []struct {
+		name       string
+		w          Warehouse
+		wantFields []string
+	}{
+		{"valid", Warehouse{Name: "north", Latitude: &lat, Longitude: &lon}, nil},
+		{"no location", Warehouse{Name: "north"}, nil},
+		{"empty name", Warehouse{}, []string{"name"}},
+		{"negative priority", Warehouse{Name: "north", Priority: -1}, []string{"priority"}},
+		{"latitude only", Warehouse{Name: "north", Latitude: &lat}, []string{"longitude"}},
+		{"longitude out of range", Warehouse{Name: "north", Latitude: &lat, Longitude: &far}, []string{"longitude"}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			checkFields(t, validateStruct(&tt.w), tt.wantFields)
+		})
+	}
+}
+
+// checkFields сравнивает поля ошибки проверки с ожидаемыми
+func checkFields(t *testing.T, err error, want []string) {
+	t.Helper()
+
+	if want == nil {
+		if err != nil {
+			t.Errorf("Expected no error, got %v", err)
+		}
+		return
+	}
+
+	var e *Error
+	if !errors.As(err, &e) || !errors.Is(err, ErrValidation) {
+		t.Fatalf("Expected ErrValidation, got %v", err)
+	}
+	if len(e.Fields) != len(want) {
+		t.Fatalf("Expected fields %v, got %+v", want, e.Fields)
+	}
+	for i, f := range e.Fields {
+		if f.Field != want[i] {
+			t.Errorf("Expected field %q, got %q", want[i], f.Field)
+		}
+	}
+}
@@ -1,59 +1,199 @@
 package controller
 
-//	@title			Warehouse API Documentation
-//	@description	This is a sample API for a warehouse application
-//	@version		1
-//	@host			localhost:8080
-
-import (
-	"database/sql"
-	"errors"
-)
+import "lamoda-test/pkg/metrics"
 
-// Product структура продукта
+// Product структура продукта.
+// Quantity - физический остаток на складе, ReservedQuantity - часть остатка,
+// обещанная заказам, Available - остаток, доступный для резервирования
 type Product struct {
-	ID          int    `json:"id"`
-	Name        string `json:"name"`
-	Size        string `json:"size"`
-	Code        string `json:"code"`
-	Quantity    int    `json:"quantity"`
-	WarehouseID int    `json:"warehouse_id"`
+	ID               int    `json:"id"`
+	Name             string `json:"name" validate:"required,max=255"`
+	Size             string `json:"size" validate:"omitempty,product_size"`
+	Code             string `json:"code" validate:"required,product_code"`
+	Quantity         int    `json:"quantity" validate:"min=0"`
+	ReservedQuantity int    `json:"reserved_quantity"`
+	Available        int    `json:"available"`
+	WarehouseID      int    `json:"warehouse_id" validate:"gt=0"`
 }
 
-// Warehouse структура склада
+// Warehouse структура склада.
+// Priority и координаты используются стратегиями распределения резерва по складам,
+// координаты задаются парой
 type Warehouse struct {
-	ID          int    `json:"id" db:"id"`
-	Name        string `json:"name" db:"name"`
-	IsAvailable bool   `json:"is_available" db:"is_available"`
+	ID          int      `json:"id" db:"id"`
+	Name        string   `json:"name" db:"name" validate:"required,max=255"`
+	IsAvailable bool     `json:"is_available" db:"is_available"`
+	Priority    int      `json:"priority" db:"priority" validate:"min=0"`
+	Latitude    *float64 `json:"latitude,omitempty" db:"latitude" validate:"required_with=Longitude,omitempty,min=-90,max=90"`
+	Longitude   *float64 `json:"longitude,omitempty" db:"longitude" validate:"required_with=Latitude,omitempty,min=-180,max=180"`
+}
+
+// WarehousePatch изменяемые поля склада, nil - поле не меняется
+type WarehousePatch struct {
+	Name        *string  `json:"name"`
+	IsAvailable *bool    `json:"is_available"`
+	Priority    *int     `json:"priority"`
+	Latitude    *float64 `json:"latitude"`
+	Longitude   *float64 `json:"longitude"`
 }
 
+var (
+	// ErrWarehouseNotFound возвращается, если склада с таким ID нет
+	ErrWarehouseNotFound = &Error{Kind: KindNotFound, Code: "warehouse_not_found", Message: "warehouse not found"}
+	// ErrWarehouseNotEmpty возвращается при попытке удалить склад, на котором остались продукты
+	ErrWarehouseNotEmpty = &Error{Kind: KindConflict, Code: "warehouse_not_empty", Message: "warehouse still holds products"}
+	// ErrWarehouseUnavailable возвращается при попытке зарезервировать, принять или переместить продукт на недоступный склад
+	ErrWarehouseUnavailable = &Error{Kind: KindConflict, Code: "warehouse_unavailable", Message: "warehouse is unavailable"}
+)
+
 //	@Summary		Create a new warehouse.
 //	@Description	Create a new warehouse in the database.
 //	@Tags			warehouses
 //	@Accept			json
 //	@Produce		json
-//	@Param			warehouse	body		Warehouse		true	"Warehouse information"
-//	@Success		200			{string}	string			"Warehouse created"
-//	@Failure		400			{object}	ErrorResponse	"Invalid request format"
-//	@Failure		500			{object}	ErrorResponse	"Internal server error"
-//	@Router			/create-warehouse [post]
+//	@Param			warehouse	body		Warehouse			true	"Warehouse information"
+//	@Success		200			{string}	string				"Warehouse created"
+//	@Failure		400			{object}	route.ErrorResponse	"Invalid request format"
+//	@Failure		422			{object}	route.ErrorResponse	"Validation failed"
+//	@Failure		500			{object}	route.ErrorResponse	"Internal server error"
+//	@Router			/warehouses [post]
 //
-// CreateWarehouse создает новый склад и записывает в базу
-func CreateWarehouse(db *sql.DB, w *Warehouse) error {
-	// Подготовка запроса для вставки нового склада
-	stmt, err := db.Prepare("INSERT INTO warehouse(name, is_available) VALUES($1, $2) RETURNING id")
-	if err != nil {
+// CreateWarehouse создает новый склад и записывает в хранилище
+func CreateWarehouse(s Store, w *Warehouse) error {
+	if err := validateStruct(w); err != nil {
 		return err
 	}
-	defer stmt.Close()
 
-	// Вставка нового склада и получение его идентификатора
-	err = stmt.QueryRow(w.Name, w.IsAvailable).Scan(&w.ID)
+	return s.Warehouses().Create(w)
+}
+
+//	@Summary		List warehouses
+//	@Description	List all warehouses ordered by ID.
+//	@Tags			warehouses
+//	@Produce		json
+//	@Success		200	{array}		Warehouse
+//	@Failure		500	{object}	route.ErrorResponse	"Internal server error"
+//	@Router			/warehouses [get]
+//
+// ListWarehouses возвращает все склады
+func ListWarehouses(s Store) ([]Warehouse, error) {
+	return s.Warehouses().List()
+}
+
+//	@Summary		Get a warehouse
+//	@Description	Get a warehouse by its ID.
+//	@Tags			warehouses
+//	@Produce		json
+//	@Param			id	path		int	true	"Warehouse ID"
+//	@Success		200	{object}	Warehouse
+//	@Failure		400	{object}	route.ErrorResponse	"Invalid request format"
+//	@Failure		404	{object}	route.ErrorResponse	"Warehouse not found"
+//	@Failure		500	{object}	route.ErrorResponse	"Internal server error"
+//	@Router			/warehouses/{id} [get]
+//
+// GetWarehouse возвращает склад по ID
+func GetWarehouse(s Store, id int) (*Warehouse, error) {
+	return s.Warehouses().Get(id)
+}
+
+//	@Summary		Update a warehouse
+//	@Description	Partially update a warehouse: rename it, toggle availability, change priority or location.
+//	@Tags			warehouses
+//	@Accept			json
+//	@Produce		json
+//	@Param			id			path		int				true	"Warehouse ID"
+//	@Param			warehouse	body		WarehousePatch	true	"Fields to update"
+//	@Success		200			{object}	Warehouse
+//	@Failure		400			{object}	route.ErrorResponse	"Invalid request format"
+//	@Failure		404			{object}	route.ErrorResponse	"Warehouse not found"
+//	@Failure		422			{object}	route.ErrorResponse	"Validation failed"
+//	@Failure		500			{object}	route.ErrorResponse	"Internal server error"
+//	@Router			/warehouses/{id} [patch]
+//
+// UpdateWarehouse частично обновляет склад
+func UpdateWarehouse(s Store, id int, patch *WarehousePatch) (*Warehouse, error) {
+	var w *Warehouse
+	err := s.InTx(func(tx Repositories) error {
+		var err error
+		w, err = tx.Warehouses().GetForUpdate(id)
+		if err != nil {
+			return err
+		}
+
+		if patch.Name != nil {
+			w.Name = *patch.Name
+		}
+		if patch.IsAvailable != nil {
+			w.IsAvailable = *patch.IsAvailable
+		}
+		if patch.Priority != nil {
+			w.Priority = *patch.Priority
+		}
+		if patch.Latitude != nil {
+			w.Latitude = patch.Latitude
+		}
+		if patch.Longitude != nil {
+			w.Longitude = patch.Longitude
+		}
+
+		// Проверяем склад после изменений, координаты могли остаться без пары
+		if err := validateStruct(w); err != nil {
+			return err
+		}
+
+		return tx.Warehouses().Update(w)
+	})
 	if err != nil {
-		return err
+		return nil, err
 	}
 
-	return nil
+	return w, nil
+}
+
+//	@Summary		Delete a warehouse
+//	@Description	Delete a warehouse that holds no stock. Empty product rows are deleted with it.
+//	@Tags			warehouses
+//	@Produce		json
+//	@Param			id	path		int					true	"Warehouse ID"
+//	@Success		204	{string}	string				""
+//	@Failure		400	{object}	route.ErrorResponse	"Invalid request format"
+//	@Failure		404	{object}	route.ErrorResponse	"Warehouse not found"
+//	@Failure		409	{object}	route.ErrorResponse	"Warehouse still holds products"
+//	@Failure		500	{object}	route.ErrorResponse	"Internal server error"
+//	@Router			/warehouses/{id} [delete]
+//
+// DeleteWarehouse удаляет склад, если на нем не осталось продуктов
+func DeleteWarehouse(s Store, id int) error {
+	return s.InTx(func(tx Repositories) error {
+		// Блокируем склад, чтобы параллельно на него не зарезервировали и не завезли продукты
+		if _, err := tx.Warehouses().GetForUpdate(id); err != nil {
+			return err
+		}
+
+		products, err := tx.Products().LockByWarehouse(id)
+		if err != nil {
+			return err
+		}
+		// Склад с остатками удалять нельзя
+		for _, p := range products {
+			if p.Quantity > 0 {
+				return ErrWarehouseNotEmpty
+			}
+		}
+
+		// Пустые строки продуктов удаляются вместе со складом
+		for _, p := range products {
+			if err := tx.Products().Delete(p.ID); err != nil {
+				return err
+			}
+			err = tx.Movements().Record(StockMovement{ProductID: p.ID, WarehouseID: p.WarehouseID, Code: p.Code, Kind: MovementDelete})
+			if err != nil {
+				return err
+			}
+		}
+
+		return tx.Warehouses().Delete(id)
+	})
 }
 
 //	@Summary		Create a new product.
@@ -61,28 +201,29 @@ func CreateWarehouse(db *sql.DB, w *Warehouse) error {
 //	@Tags			products
 //	@Accept			json
 //	@Produce		json
-//	@Param			product	body		Product			true	"Product information"
-//	@Success		200		{string}	string			"Product created"
-//	@Failure		400		{object}	ErrorResponse	"Invalid request format"
-//	@Failure		500		{object}	ErrorResponse	"Internal server error"
-//	@Router			/create-product [post]
+//	@Param			product	body		Product				true	"Product information"
+//	@Success		200		{string}	string				"Product created"
+//	@Failure		400		{object}	route.ErrorResponse	"Invalid request format"
+//	@Failure		404		{object}	route.ErrorResponse	"Warehouse not found"
+//	@Failure		409		{object}	route.ErrorResponse	"Product already exists on the warehouse"
+//	@Failure		422		{object}	route.ErrorResponse	"Validation failed"
+//	@Failure		500		{object}	route.ErrorResponse	"Internal server error"
+//	@Router			/products [post]
 //
 // CreateProduct создает новый продукт на заданном складе
-func CreateProduct(db *sql.DB, p *Product) error {
-	// Подготовка запроса для вставки нового продукта
-	stmt, err := db.Prepare("INSERT INTO products(name, size, code, quantity, warehouse_id) VALUES($1, $2, $3, $4, $5) RETURNING id")
-	if err != nil {
+func CreateProduct(s Store, p *Product) error {
+	if err := validateStruct(p); err != nil {
 		return err
 	}
-	defer stmt.Close()
 
-	// Вставка нового продукта и получение его идентификатора
-	err = stmt.QueryRow(p.Name, p.Size, p.Code, p.Quantity, p.WarehouseID).Scan(&p.ID)
-	if err != nil {
-		return err
-	}
+	return s.InTx(func(tx Repositories) error {
+		if err := tx.Products().Create(p); err != nil {
+			return err
+		}
 
-	return nil
+		// Начальный остаток попадает в журнал
+		return tx.Movements().Record(StockMovement{ProductID: p.ID, WarehouseID: p.WarehouseID, Code: p.Code, Kind: MovementCreate, QuantityDelta: p.Quantity})
+	})
 }
 
 //	@Summary		Delete a product
@@ -90,171 +231,241 @@ func CreateProduct(db *sql.DB, p *Product) error {
 //	@Tags			products
 //	@Accept			json
 //	@Produce		json
-//	@Param			id	path		int				true	"Product ID"
-//	@Success		200	{string}	string			"Product deleted successfully"
-//	@Failure		400	{object}	ErrorResponse	"Invalid request format"
-//	@Failure		500	{object}	ErrorResponse	"Internal server error"
-//	@Router			/delete-product/:id [delete]
+//	@Param			id	path		int					true	"Product ID"
+//	@Success		200	{string}	string				"Product deleted successfully"
+//	@Failure		400	{object}	route.ErrorResponse	"Invalid request format"
+//	@Failure		404	{object}	route.ErrorResponse	"Product not found"
+//	@Failure		409	{object}	route.ErrorResponse	"Product has reserved units"
+//	@Failure		500	{object}	route.ErrorResponse	"Internal server error"
+//	@Router			/products/{id} [delete]
 //
-// DeleteProduct удаляет продукт по ID
-func DeleteProduct(db *sql.DB, id int) error {
-	// Удаляем продукт из базы данных
-	_, err := db.Exec("DELETE FROM products WHERE id = $1", id)
-	if err != nil {
-		return err
-	}
+// DeleteProduct удаляет продукт по ID, если он не зарезервирован
+func DeleteProduct(s Store, id int) error {
+	return s.InTx(func(tx Repositories) error {
+		// Блокируем продукт, чтобы его не зарезервировали параллельно
+		p, err := tx.Products().GetForUpdate(id)
+		if err != nil {
+			return err
+		}
+		if p.ReservedQuantity > 0 {
+			return ErrProductReserved.WithProduct(p.Code)
+		}
 
-	return nil
+		// Удаляем продукт, списанный остаток попадает в журнал
+		if err := tx.Products().Delete(id); err != nil {
+			return err
+		}
+
+		return tx.Movements().Record(StockMovement{ProductID: p.ID, WarehouseID: p.WarehouseID, Code: p.Code, Kind: MovementDelete, QuantityDelta: -p.Quantity})
+	})
 }
 
-//	@Summary		Reserves products
-//	@Description	Reserves products and updates their quantities
+//	@Summary		Releases products
+//	@Description	Releases reserved products: decreases reserved quantity, never below zero.
+//	@Description	Units held by reservations are taken from the newest active reservations; a reservation left without lines becomes released.
 //	@Tags			products
 //	@Accept			json
 //	@Produce		json
-//	@Param			productCodes	query		[]string	true	"Product codes"
-//	@Success		204				{string}	string		""
-//	@Failure		400				{object}	ErrorResponse
-//	@Failure		500				{object}	ErrorResponse
-//	@Router			/reserve-products [post]
+//	@Param			lines	body		[]ReservationLine	true	"Product codes and quantities"
+//	@Success		200		{string}	string				""
+//	@Failure		400		{object}	route.ErrorResponse
+//	@Failure		404		{object}	route.ErrorResponse
+//	@Failure		409		{object}	route.ErrorResponse
+//	@Failure		422		{object}	route.ErrorResponse
+//	@Failure		500		{object}	route.ErrorResponse
+//	@Router			/releases [post]
 //
-// ReserveProducts резервирует продукты
-func ReserveProducts(db *sql.DB, productCodes []string) error {
-	if len(productCodes) == 0 {
-		return errors.New("empty product codes")
-	}
-
-	// Начинаем транзакцию
-	tx, err := db.Begin()
-	if err != nil {
+// ReleaseProducts снимает резерв с продуктов по кодам.
+// Устаревший способ: резерв, созданный через ReserveProducts, следует снимать ReleaseReservation.
+// Резерв снимается и со строк резервирований, чтобы они не удерживали уже снятые единицы
+func ReleaseProducts(s Store, lines []ReservationLine) error {
+	// Проверяем строки на пустоту и корректность количества
+	if err := validateLines(lines); err != nil {
 		return err
 	}
+	lines = mergeLines(lines)
 
-	// Зарезервируем каждый продукт в цикле
-	for _, code := range productCodes {
-		// Заблокируем строку продукта для избежания гонки за ресурсами
-		row := tx.QueryRow("SELECT id, name, size, code, quantity FROM products WHERE code = $1 FOR UPDATE", code)
-
-		var p Product
-		err := row.Scan(&p.ID, &p.Name, &p.Size, &p.Code, &p.Quantity)
-		if err != nil {
-			tx.Rollback()
+	err := s.InTx(func(tx Repositories) error {
+		// Блокируем все продукты до резервирований, которые держат их единицы
+		warehouseIDs := map[string][]int{}
+		allWarehouses := map[string]bool{}
+		for _, l := range lines {
+			if l.WarehouseID == 0 {
+				allWarehouses[l.Code] = true
+			}
+			warehouseIDs[l.Code] = append(warehouseIDs[l.Code], l.WarehouseID)
+		}
+		for code := range allWarehouses {
+			warehouseIDs[code] = nil
+		}
+		if err := lockCodes(tx, warehouseIDs); err != nil {
 			return err
 		}
 
-		// Проверяем, доступен ли продукт для бронирования
-		if p.Quantity < 1 {
-			tx.Rollback()
-			return errors.New("product is out of stock")
-		}
+		// Проходимся по каждому продукту
+		for _, l := range lines {
+			// Перечитываем уже заблокированные строки продукта на всех складах (или на указанном)
+			var warehouseIDs []int
+			if l.WarehouseID != 0 {
+				warehouseIDs = append(warehouseIDs, l.WarehouseID)
+			}
+			products, err := tx.Products().LockByCode(l.Code, warehouseIDs...)
+			if err != nil {
+				return err
+			}
+			if len(products) == 0 {
+				return ErrProductNotFound.WithProduct(l.Code)
+			}
 
-		// Обновляем количество продукта
-		_, err = tx.Exec("UPDATE products SET quantity = quantity - 1 WHERE id = $1", p.ID)
-		if err != nil {
-			tx.Rollback()
-			return err
+			// Нельзя снять больше, чем зарезервировано
+			left := l.Quantity
+			for _, p := range products {
+				left -= p.ReservedQuantity
+			}
+			if left > 0 {
+				return ErrNotReserved.WithProduct(l.Code)
+			}
+
+			// Уменьшаем резерв, начиная с первых складов
+			left = l.Quantity
+			for _, p := range products {
+				if left == 0 {
+					break
+				}
+				released, err := releaseReserved(tx, p, left)
+				if err != nil {
+					return err
+				}
+				left -= released
+			}
 		}
-	}
 
-	// Фиксируем транзакцию
-	err = tx.Commit()
+		return nil
+	})
 	if err != nil {
 		return err
 	}
 
+	for _, l := range lines {
+		metrics.UnitsReleased.WithLabelValues(MovementRelease).Add(float64(l.Quantity))
+	}
+
 	return nil
 }
 
-//	@Summary		Releases products
-//	@Description	Releases reserved products and updates their quantities
-//	@Tags			products
-//	@Accept			json
-//	@Produce		json
-//	@Param			productCodes	query		[]string	true	"Product codes"
-//	@Success		204				{string}	string		""
-//	@Failure		400				{object}	ErrorResponse
-//	@Failure		500				{object}	ErrorResponse
-//	@Router			/release-products [post]
-//
-// ReleaseProducts реализует товаровы
-func ReleaseProducts(db *sql.DB, productCodes []string) error {
-	// Проверяем массив на пустоту массива кодов
-	if len(productCodes) == 0 {
-		return errors.New("empty product codes")
+// releaseReserved снимает до quantity единиц резерва продукта p и возвращает, сколько снято.
+// Сначала снимается резерв, который не принадлежит резервированиям, затем строки активных резервирований
+// от новых к старым. Резервирование, у которого не осталось строк, считается снятым
+func releaseReserved(tx Repositories, p Product, quantity int) (int, error) {
+	if quantity > p.ReservedQuantity {
+		quantity = p.ReservedQuantity
 	}
-
-	// Начинаем новую транзакцию
-	tx, err := db.Begin()
-	if err != nil {
-		return err
+	if quantity == 0 {
+		return 0, nil
 	}
 
-	// Создаем новую транзакцию
-	updateStmt, err := tx.Prepare("UPDATE products SET quantity = quantity + 1 WHERE code = $1")
+	holding, err := tx.Reservations().ListHolding(p.ID)
 	if err != nil {
-		tx.Rollback()
-		return err
+		return 0, err
+	}
+	held := 0
+	for _, r := range holding {
+		held += heldQuantity(r.Lines, p.ID)
 	}
-	defer updateStmt.Close()
 
-	// Проходимся по каждому продукту
-	for _, code := range productCodes {
-		// Проверяем существует ли продукт
-		var p Product
-		err := db.QueryRow("SELECT id, name, size, code, quantity FROM products WHERE code = $1", code).Scan(&p.ID, &p.Name, &p.Size, &p.Code, &p.Quantity)
-		if err != nil {
-			tx.Rollback()
+	released := 0
+	release := func(take int, reservationID *int) error {
+		if err := tx.Products().AddStock(p.ID, 0, -take); err != nil {
 			return err
 		}
+		released += take
 
-		// Обновляем количество продукта
-		_, err = updateStmt.Exec(p.Code)
-		if err != nil {
-			tx.Rollback()
-			return err
+		return tx.Movements().Record(StockMovement{ProductID: p.ID, WarehouseID: p.WarehouseID, Code: p.Code, Kind: MovementRelease, ReservedDelta: -take, ReservationID: reservationID})
+	}
+
+	if free := p.ReservedQuantity - held; free > 0 {
+		if free > quantity {
+			free = quantity
+		}
+		if err := release(free, nil); err != nil {
+			return 0, err
 		}
 	}
 
-	// Фиксируем транзакцию
-	err = tx.Commit()
-	if err != nil {
-		tx.Rollback()
-		return err
+	for i := range holding {
+		if released == quantity {
+			break
+		}
+		r := &holding[i]
+		take := heldQuantity(r.Lines, p.ID)
+		if take > quantity-released {
+			take = quantity - released
+		}
+		if err := tx.Reservations().ReduceLines(r.ID, p.ID, take); err != nil {
+			return 0, err
+		}
+		if err := release(take, &r.ID); err != nil {
+			return 0, err
+		}
+
+		total := 0
+		for _, l := range r.Lines {
+			total += l.Quantity
+		}
+		if total == take {
+			if err := tx.Reservations().SetStatus(r, ReservationReleased); err != nil {
+				return 0, err
+			}
+		}
 	}
 
-	return nil
+	return released, nil
 }
 
-// @Description Get remaining products for a given warehouse.
-// @Tags products
-// @Accept json
-// @Produce json
-// @Param warehouseID path int true "Warehouse ID"
-// @Success 200 {array} Product "Remaining products"
-// @Failure 400 {object} ErrorResponse "Invalid request format"
-// @Failure 500 {object} ErrorResponse "Internal server error"
-// @Router /remaining-products/{warehouseID} [get]
-// GetRemainingProducts возвращает оставшееся количество продуктов на складе
-func GetRemainingProducts(db *sql.DB, warehouseID int) ([]Product, error) {
-	// Проходимся по строкам, возвращенным запросом, и добавляем каждую строку к слайсу продуктов.
-	rows, err := db.Query("SELECT code, quantity FROM products WHERE warehouse_id = $1", warehouseID)
-	if err != nil {
-		return nil, err
-	}
-	defer rows.Close()
-	// Создаем пустой слайс для хранения результатов
-	var products []Product
-	for rows.Next() {
-		var p Product
-		if err := rows.Scan(&p.Code, &p.Quantity); err != nil {
-			return nil, err
+// heldQuantity возвращает, сколько единиц продукта productID держат строки резервирования
+func heldQuantity(lines []ReservationLine, productID int) int {
+	held := 0
+	for _, l := range lines {
+		if l.ProductID == productID {
+			held += l.Quantity
 		}
-		p.WarehouseID = warehouseID
-		products = append(products, p)
 	}
-	if err := rows.Err(); err != nil {
+
+	return held
+}
+
+//	@Summary		Get remaining products
+//	@Description	Get remaining products for a given warehouse: on-hand, reserved and available quantities.
+//	@Description	Supports the same filters, sorting and pagination as the product list.
+//	@Tags			products
+//	@Accept			json
+//	@Produce		json
+//	@Param			id				path		int					true	"Warehouse ID"
+//	@Param			name			query		string				false	"Name substring"
+//	@Param			size			query		string				false	"Size"
+//	@Param			out_of_stock	query		bool				false	"Only products without available units"
+//	@Param			sort			query		string				false	"Sort field, prefix - for descending"
+//	@Param			limit			query		int					false	"Page size"
+//	@Param			offset			query		int					false	"Page offset"
+//	@Success		200				{object}	ProductPage			"Remaining products"
+//	@Failure		400				{object}	route.ErrorResponse	"Invalid request format"
+//	@Failure		404				{object}	route.ErrorResponse	"Warehouse not found"
+//	@Failure		422				{object}	route.ErrorResponse	"Invalid pagination or sort"
+//	@Failure		500				{object}	route.ErrorResponse	"Internal server error"
+//	@Router			/warehouses/{id}/stock [get]
+//
+// GetRemainingProducts возвращает страницу оставшихся на складе продуктов
+func GetRemainingProducts(s Store, warehouseID int, f *ProductFilter) (*ProductPage, error) {
+	// Проверяем, что склад существует, иначе пустой список неотличим от неизвестного склада
+	if _, err := GetWarehouse(s, warehouseID); err != nil {
 		return nil, err
 	}
 
-	return products, nil
+	filter := ProductFilter{}
+	if f != nil {
+		filter = *f
+	}
+	filter.WarehouseID = warehouseID
+
+	return ListProducts(s, &filter)
 }
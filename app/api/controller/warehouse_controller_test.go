@@ -1,29 +1,25 @@
-package controller
+package controller_test
 
 import (
-	"database/sql"
-	"lamoda-test/utils"
+	"errors"
+	"sync"
 	"testing"
 
-	_ "github.com/lib/pq"
+	"lamoda-test/api/controller"
+	"lamoda-test/utils"
 )
 
 func TestCreateWarehouse(t *testing.T) {
-	// Коннектимся к базе
-	db, err := sql.Open("postgres", "host=localhost port=5432 user=root password=secret dbname=lamoda_db sslmode=disable")
-	if err != nil {
-		t.Fatal(err)
-	}
-	defer db.Close()
+	store := newStore(t)
 
 	// Создам новый склад
-	w := &Warehouse{
+	w := &controller.Warehouse{
 		Name:        utils.RandomString(6),
 		IsAvailable: true,
 	}
 
 	// Вызываем функцию создания нового склада
-	err = CreateWarehouse(db, w)
+	err := controller.CreateWarehouse(store, w)
 	if err != nil {
 		t.Fatal(err)
 	}
@@ -35,36 +31,31 @@ func TestCreateWarehouse(t *testing.T) {
 }
 
 func TestCreateProduct(t *testing.T) {
-	// Подключаемся к базе
-	db, err := sql.Open("postgres", "host=localhost port=5432 user=root password=secret dbname=lamoda_db sslmode=disable")
-	if err != nil {
-		t.Fatal(err)
-	}
-	defer db.Close()
+	store := newStore(t)
 
 	// Создаем новый склад (снова т.к мы не знаем айдишник и название склада заренее)
-	w := &Warehouse{
+	w := &controller.Warehouse{
 		Name:        utils.RandomString(6),
 		IsAvailable: true,
 	}
 
 	// Вызываем функцию для создания склада
-	err = CreateWarehouse(db, w)
+	err := controller.CreateWarehouse(store, w)
 	if err != nil {
 		t.Fatal(err)
 	}
 
 	// Создаем продукт
-	p := &Product{
+	p := &controller.Product{
 		Name:        utils.RandomString(6),
-		Size:        utils.RandomString(6),
+		Size:        utils.RandomSize(),
 		Code:        utils.RandomString(6),
 		Quantity:    utils.RandomInt(6),
 		WarehouseID: w.ID,
 	}
 
 	// Вызываем функцию создания продукта
-	err = CreateProduct(db, p)
+	err = controller.CreateProduct(store, p)
 	if err != nil {
 		t.Fatal(err)
 	}
@@ -76,93 +67,312 @@ func TestCreateProduct(t *testing.T) {
 }
 
 func TestReserveProductsEmptyProductCodes(t *testing.T) {
-	// Подключаемся к базе
-	db, err := sql.Open("postgres", "host=localhost port=5432 user=root password=secret dbname=lamoda_db sslmode=disable")
-	if err != nil {
-		t.Fatal(err)
-	}
-	defer db.Close()
+	store := newStore(t)
 
-	err = ReserveProducts(db, []string{})
+	err := controller.ReserveProducts(store, &controller.Reservation{OrderRef: utils.RandomString(6)})
 	if err == nil {
 		t.Error("Expected an error with empty product codes, but got nil")
 	}
 }
 
 func TestReserveProductsInvalidProductCode(t *testing.T) {
-	// Подключаемся к базе
-	db, err := sql.Open("postgres", "host=localhost port=5432 user=root password=secret dbname=lamoda_db sslmode=disable")
-	if err != nil {
-		t.Fatal(err)
-	}
-	defer db.Close()
+	store := newStore(t)
 
 	// Создаем новый склад
-	w := &Warehouse{
+	w := &controller.Warehouse{
 		Name:        utils.RandomString(6),
 		IsAvailable: true,
 	}
-	err = CreateWarehouse(db, w)
+	err := controller.CreateWarehouse(store, w)
 	if err != nil {
 		t.Fatal(err)
 	}
 
 	// Создаем новый продукт и добавляем его на склад
-	p := &Product{
+	p := &controller.Product{
 		Name:        utils.RandomString(6),
-		Size:        utils.RandomString(6),
+		Size:        utils.RandomSize(),
 		Code:        utils.RandomString(6),
 		Quantity:    1,
 		WarehouseID: w.ID,
 	}
-	err = CreateProduct(db, p)
+	err = controller.CreateProduct(store, p)
 	if err != nil {
 		t.Fatal(err)
 	}
 
 	// Пытаемся зарезервировать продукт с неверным кодом
-	err = ReserveProducts(db, []string{"invalid-code"})
+	err = controller.ReserveProducts(store, &controller.Reservation{
+		OrderRef: utils.RandomString(6),
+		Lines:    []controller.ReservationLine{{Code: "invalid-code", Quantity: 1}},
+	})
 	if err == nil {
 		t.Error("Expected an error with invalid product code, but got nil")
 	}
 }
 
 func TestReserveProductsProductOutOfStock(t *testing.T) {
-	// Подключаемся к базе
-	db, err := sql.Open("postgres", "host=localhost port=5432 user=root password=secret dbname=lamoda_db sslmode=disable")
-	if err != nil {
-		t.Fatal(err)
-	}
-	defer db.Close()
+	store := newStore(t)
 
 	// Создаем новый склад
-	w := &Warehouse{
+	w := &controller.Warehouse{
 		Name:        utils.RandomString(6),
 		IsAvailable: true,
 	}
-	err = CreateWarehouse(db, w)
+	err := controller.CreateWarehouse(store, w)
 	if err != nil {
 		t.Fatal(err)
 	}
 
 	// Создаем новый продукт и добавляем его на склад
-	p := &Product{
+	p := &controller.Product{
 		Name:        utils.RandomString(6),
-		Size:        utils.RandomString(6),
+		Size:        utils.RandomSize(),
 		Code:        utils.RandomString(6),
 		Quantity:    0, // устанавливаем количество 0, чтобы продукт был недоступен для бронирования
 		WarehouseID: w.ID,
 	}
-	err = CreateProduct(db, p)
+	err = controller.CreateProduct(store, p)
 	if err != nil {
 		t.Fatal(err)
 	}
 
 	// Пытаемся зарезервировать продукт, который отсутствует на складе
-	err = ReserveProducts(db, []string{p.Code})
+	err = controller.ReserveProducts(store, &controller.Reservation{
+		OrderRef: utils.RandomString(6),
+		Lines:    []controller.ReservationLine{{Code: p.Code, Quantity: 1}},
+	})
 	if err == nil {
 		t.Errorf("Expected error, but got nil")
 	} else if err.Error() != "product is out of stock" {
 		t.Errorf("Expected error message 'product is out of stock', but got '%s'", err.Error())
 	}
 }
+
+func TestReserveProductsKeepsOnHandQuantity(t *testing.T) {
+	store := newStore(t)
+
+	// Создаем новый склад
+	w := &controller.Warehouse{
+		Name:        utils.RandomString(6),
+		IsAvailable: true,
+	}
+	err := controller.CreateWarehouse(store, w)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// Создаем продукт с двумя единицами на складе
+	p := &controller.Product{
+		Name:        utils.RandomString(6),
+		Size:        utils.RandomSize(),
+		Code:        utils.RandomString(6),
+		Quantity:    2,
+		WarehouseID: w.ID,
+	}
+	err = controller.CreateProduct(store, p)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// Резервируем одну единицу
+	err = controller.ReserveProducts(store, &controller.Reservation{
+		OrderRef: utils.RandomString(6),
+		Lines:    []controller.ReservationLine{{Code: p.Code, Quantity: 1}},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	page, err := controller.GetRemainingProducts(store, w.ID, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(page.Items) != 1 {
+		t.Fatalf("Expected 1 product, got %d", len(page.Items))
+	}
+
+	// Физический остаток не меняется, резерв и доступный остаток считаются отдельно
+	got := page.Items[0]
+	if got.Quantity != 2 || got.ReservedQuantity != 1 || got.Available != 1 {
+		t.Errorf("Expected quantity 2, reserved 1, available 1, got %d, %d, %d", got.Quantity, got.ReservedQuantity, got.Available)
+	}
+}
+
+func TestReleaseProductsAboveReserved(t *testing.T) {
+	store := newStore(t)
+
+	// Создаем новый склад
+	w := &controller.Warehouse{
+		Name:        utils.RandomString(6),
+		IsAvailable: true,
+	}
+	err := controller.CreateWarehouse(store, w)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// Создаем продукт без резерва
+	p := &controller.Product{
+		Name:        utils.RandomString(6),
+		Size:        utils.RandomSize(),
+		Code:        utils.RandomString(6),
+		Quantity:    5,
+		WarehouseID: w.ID,
+	}
+	err = controller.CreateProduct(store, p)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// Пытаемся снять резерв, которого не было
+	err = controller.ReleaseProducts(store, []controller.ReservationLine{{Code: p.Code, Quantity: 1}})
+	if err == nil {
+		t.Error("Expected an error when releasing unreserved product, but got nil")
+	}
+}
+
+func TestReserveProductsNonPositiveQuantity(t *testing.T) {
+	store := newStore(t)
+
+	// Количество проверяется до обращения к хранилищу
+	for _, quantity := range []int{0, -1} {
+		err := controller.ReserveProducts(store, &controller.Reservation{
+			OrderRef: utils.RandomString(6),
+			Lines:    []controller.ReservationLine{{Code: utils.RandomString(6), Quantity: quantity}},
+		})
+		if !errors.Is(err, controller.ErrInvalidQuantity) {
+			t.Errorf("Expected ErrInvalidQuantity for quantity %d, got %v", quantity, err)
+		}
+	}
+}
+
+func TestUpdateWarehouse(t *testing.T) {
+	store := newStore(t)
+
+	w := &controller.Warehouse{
+		Name:        utils.RandomString(6),
+		IsAvailable: true,
+	}
+	err := controller.CreateWarehouse(store, w)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// Переименовываем склад и закрываем его, приоритет не меняется
+	name := utils.RandomString(6)
+	available := false
+	updated, err := controller.UpdateWarehouse(store, w.ID, &controller.WarehousePatch{Name: &name, IsAvailable: &available})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if updated.Name != name || updated.IsAvailable || updated.Priority != w.Priority {
+		t.Errorf("Unexpected warehouse after update: %+v", updated)
+	}
+
+	got, err := controller.GetWarehouse(store, w.ID)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got.Name != name || got.IsAvailable {
+		t.Errorf("Expected updated warehouse to be stored, got %+v", got)
+	}
+
+	_, err = controller.UpdateWarehouse(store, -1, &controller.WarehousePatch{Name: &name})
+	if !errors.Is(err, controller.ErrWarehouseNotFound) {
+		t.Errorf("Expected ErrWarehouseNotFound, got %v", err)
+	}
+}
+
+func TestDeleteWarehouse(t *testing.T) {
+	store := newStore(t)
+
+	w := &controller.Warehouse{
+		Name:        utils.RandomString(6),
+		IsAvailable: true,
+	}
+	err := controller.CreateWarehouse(store, w)
+	if err != nil {
+		t.Fatal(err)
+	}
+	p := &controller.Product{
+		Name:        utils.RandomString(6),
+		Size:        utils.RandomSize(),
+		Code:        utils.RandomString(6),
+		Quantity:    1,
+		WarehouseID: w.ID,
+	}
+	err = controller.CreateProduct(store, p)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// Склад с остатками не удаляется
+	err = controller.DeleteWarehouse(store, w.ID)
+	if !errors.Is(err, controller.ErrWarehouseNotEmpty) {
+		t.Fatalf("Expected ErrWarehouseNotEmpty, got %v", err)
+	}
+
+	// После удаления продукта склад удаляется
+	err = controller.DeleteProduct(store, p.ID)
+	if err != nil {
+		t.Fatal(err)
+	}
+	err = controller.DeleteWarehouse(store, w.ID)
+	if err != nil {
+		t.Fatal(err)
+	}
+	_, err = controller.GetWarehouse(store, w.ID)
+	if !errors.Is(err, controller.ErrWarehouseNotFound) {
+		t.Errorf("Expected ErrWarehouseNotFound, got %v", err)
+	}
+}
+
+func TestDeleteWarehouseConcurrentWithReserve(t *testing.T) {
+	store := newStore(t)
+
+	w := &controller.Warehouse{
+		Name:        utils.RandomString(6),
+		IsAvailable: true,
+	}
+	err := controller.CreateWarehouse(store, w)
+	if err != nil {
+		t.Fatal(err)
+	}
+	p := &controller.Product{
+		Name:        utils.RandomString(6),
+		Size:        utils.RandomSize(),
+		Code:        utils.RandomString(6),
+		Quantity:    100,
+		WarehouseID: w.ID,
+	}
+	err = controller.CreateProduct(store, p)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// Удаление склада и параллельное резервирование на нем не должны взаимоблокироваться
+	for i := 0; i < 20; i++ {
+		var wg sync.WaitGroup
+		var deleteErr, reserveErr error
+		wg.Add(2)
+		go func() {
+			defer wg.Done()
+			deleteErr = controller.DeleteWarehouse(store, w.ID)
+		}()
+		go func() {
+			defer wg.Done()
+			reserveErr = controller.ReserveProducts(store, &controller.Reservation{
+				OrderRef: utils.RandomString(6),
+				Lines:    []controller.ReservationLine{{Code: p.Code, Quantity: 1}},
+			})
+		}()
+		wg.Wait()
+		if !errors.Is(deleteErr, controller.ErrWarehouseNotEmpty) {
+			t.Fatalf("Expected ErrWarehouseNotEmpty, got %v", deleteErr)
+		}
+		if reserveErr != nil {
+			t.Fatalf("Expected reserve to succeed, got %v", reserveErr)
+		}
+	}
+}
@@ -0,0 +1,29 @@
+package route
+
+import (
+	"net/http"
+
+	"lamoda-test/api/controller"
+
+	"github.com/gin-gonic/gin"
+)
+
+// adjustmentRoutes регистрирует обработчики корректировок остатка
+func adjustmentRoutes(r routes, store controller.Store) {
+	// Корректировка остатка после пересчета, порчи или потери
+	r.handle(http.MethodPost, "/adjustments", "/adjust-products", func(c *gin.Context) {
+		var a controller.Adjustment
+		if err := c.ShouldBindJSON(&a); err != nil {
+			abortWithBadRequest(c, "invalid request body")
+			return
+		}
+
+		err := controller.AdjustProduct(store, &a)
+		if err != nil {
+			abortWithError(c, err)
+			return
+		}
+
+		c.JSON(http.StatusCreated, a)
+	})
+}
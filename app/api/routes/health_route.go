@@ -0,0 +1,115 @@
+package route
+
+import (
+	"context"
+	"database/sql"
+	"net/http"
+	"sync/atomic"
+	"time"
+
+	"github.com/gin-gonic/gin"
+)
+
+// Health состояние приложения для проб оркестратора
+type Health struct {
+	// db нужен только для проверки готовности, при хранилище в памяти nil
+	db      *sql.DB
+	timeout time.Duration
+
+	started  atomic.Bool
+	stopping atomic.Bool
+}
+
+// NewHealth создает состояние проб, ping базы ограничен timeout
+func NewHealth(db *sql.DB, timeout time.Duration) *Health {
+	return &Health{db: db, timeout: timeout}
+}
+
+// Started отмечает, что приложение запущено и принимает запросы
+func (h *Health) Started() {
+	h.started.Store(true)
+}
+
+// Stopping отмечает начало плавной остановки, после него приложение перестает быть готовым
+func (h *Health) Stopping() {
+	h.stopping.Store(true)
+}
+
+// PoolStats состояние пула соединений с базой
+type PoolStats struct {
+	MaxOpenConnections int           `json:"max_open_connections"`
+	OpenConnections    int           `json:"open_connections"`
+	InUse              int           `json:"in_use"`
+	Idle               int           `json:"idle"`
+	WaitCount          int64         `json:"wait_count"`
+	WaitDuration       time.Duration `json:"wait_duration"`
+}
+
+// HealthResponse ответ пробы. Database и Pool заполняются только при хранилище postgres
+type HealthResponse struct {
+	Status   string     `json:"status"`
+	Database string     `json:"database,omitempty"`
+	Error    string     `json:"error,omitempty"`
+	Pool     *PoolStats `json:"pool,omitempty"`
+}
+
+// healthRoutes регистрирует пробы живости, запуска и готовности
+func healthRoutes(r *gin.Engine, h *Health) {
+	// Процесс жив и обрабатывает запросы, база не проверяется, чтобы ее сбой не перезапускал приложение
+	r.GET("/livez", func(c *gin.Context) {
+		c.JSON(http.StatusOK, HealthResponse{Status: "ok"})
+	})
+
+	// Приложение завершило запуск: подключилось к базе, применило миграции и слушает порт
+	r.GET("/startupz", func(c *gin.Context) {
+		if !h.started.Load() {
+			c.JSON(http.StatusServiceUnavailable, HealthResponse{Status: "starting"})
+			return
+		}
+
+		c.JSON(http.StatusOK, HealthResponse{Status: "ok"})
+	})
+
+	// Приложение готово принимать трафик: запущено, не останавливается и база отвечает
+	r.GET("/readyz", func(c *gin.Context) {
+		switch {
+		case h.stopping.Load():
+			c.JSON(http.StatusServiceUnavailable, HealthResponse{Status: "stopping"})
+			return
+		case !h.started.Load():
+			c.JSON(http.StatusServiceUnavailable, HealthResponse{Status: "starting"})
+			return
+		case h.db == nil:
+			c.JSON(http.StatusOK, HealthResponse{Status: "ok"})
+			return
+		}
+
+		resp := HealthResponse{Status: "ok", Database: "ok", Pool: poolStats(h.db)}
+
+		ctx, cancel := context.WithTimeout(c.Request.Context(), h.timeout)
+		defer cancel()
+		if err := h.db.PingContext(ctx); err != nil {
+			_ = c.Error(err)
+			resp.Status = "unavailable"
+			resp.Database = "unavailable"
+			resp.Error = err.Error()
+			c.JSON(http.StatusServiceUnavailable, resp)
+			return
+		}
+
+		c.JSON(http.StatusOK, resp)
+	})
+}
+
+// poolStats снимает состояние пула соединений
+func poolStats(db *sql.DB) *PoolStats {
+	s := db.Stats()
+	return &PoolStats{
+		MaxOpenConnections: s.MaxOpenConnections,
+		OpenConnections:    s.OpenConnections,
+		InUse:              s.InUse,
+		Idle:               s.Idle,
+		WaitCount:          s.WaitCount,
+		WaitDuration:       s.WaitDuration,
+	}
+}
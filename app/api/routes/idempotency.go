@@ -0,0 +1,111 @@
+package route
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"encoding/hex"
+	"io"
+	"net/http"
+	"time"
+
+	"lamoda-test/api/controller"
+
+	"github.com/gin-gonic/gin"
+)
+
+// Заголовки идемпотентных запросов
+const (
+	idempotencyKeyHeader      = "Idempotency-Key"
+	idempotencyReplayedHeader = "Idempotent-Replayed"
+)
+
+// maxIdempotencyKeyLength ограничение длины ключа идемпотентности
+const maxIdempotencyKeyLength = 255
+
+// recordingWriter копирует тело ответа, чтобы сохранить его для повторов
+type recordingWriter struct {
+	gin.ResponseWriter
+	body bytes.Buffer
+}
+
+func (w *recordingWriter) Write(b []byte) (int, error) {
+	w.body.Write(b)
+	return w.ResponseWriter.Write(b)
+}
+
+func (w *recordingWriter) WriteString(s string) (int, error) {
+	w.body.WriteString(s)
+	return w.ResponseWriter.WriteString(s)
+}
+
+// idempotency повторяет сохраненный ответ для изменяющих запросов с уже использованным заголовком Idempotency-Key.
+// Ответы 5xx не сохраняются, такой запрос можно повторить с тем же ключом
+func idempotency(store controller.Store, ttl time.Duration) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		key := c.GetHeader(idempotencyKeyHeader)
+		if key == "" || c.Request.Method == http.MethodGet || c.Request.Method == http.MethodHead || c.Request.Method == http.MethodOptions {
+			c.Next()
+			return
+		}
+		if len(key) > maxIdempotencyKeyLength {
+			abortWithBadRequest(c, "idempotency key is too long")
+			return
+		}
+
+		// Читаем тело для хеша и возвращаем его обработчику
+		body, err := io.ReadAll(c.Request.Body)
+		if err != nil {
+			abortWithBadRequest(c, "invalid request body")
+			return
+		}
+		c.Request.Body = io.NopCloser(bytes.NewReader(body))
+		hash := sha256.Sum256(body)
+
+		req := &controller.IdempotentRequest{
+			Key:         key,
+			Method:      c.Request.Method,
+			Path:        c.Request.URL.Path,
+			RequestHash: hex.EncodeToString(hash[:]),
+		}
+		stored, err := controller.ClaimIdempotencyKey(store, req, ttl)
+		if err != nil {
+			abortWithError(c, err)
+			return
+		}
+		if stored != nil {
+			c.Header(idempotencyReplayedHeader, "true")
+			c.Data(stored.Status, stored.ContentType, stored.Body)
+			c.Abort()
+			return
+		}
+
+		// Если ответ не сохранен (ответ 5xx, ошибка сохранения или паника в обработчике),
+		// ключ освобождается, чтобы повтор не ждал idempotencyLockTimeout
+		saved := false
+		defer func() {
+			if saved {
+				return
+			}
+			if err := controller.ReleaseIdempotencyKey(store, req); err != nil {
+				_ = c.Error(err)
+			}
+		}()
+
+		w := &recordingWriter{ResponseWriter: c.Writer}
+		c.Writer = w
+		c.Next()
+
+		if w.Status() >= http.StatusInternalServerError {
+			return
+		}
+
+		req.Status = w.Status()
+		req.ContentType = w.Header().Get("Content-Type")
+		req.Body = w.body.Bytes()
+		if err := controller.SaveIdempotentResponse(store, req); err != nil {
+			_ = c.Error(err)
+			return
+		}
+		saved = true
+	}
+}
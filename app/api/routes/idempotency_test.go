@@ -0,0 +1,149 @@
+package route
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"lamoda-test/api/controller"
+	"lamoda-test/pkg/client/memory"
+
+	"github.com/gin-gonic/gin"
+)
+
+// newTestRouter создает роутер поверх хранилища в памяти
+func newTestRouter(store controller.Store) *gin.Engine {
+	gin.SetMode(gin.TestMode)
+	return NewRouter(store, NewHealth(nil, time.Second), time.Hour)
+}
+
+// doRequest выполняет запрос к роутеру, пустой key отправляется без Idempotency-Key
+func doRequest(r http.Handler, method, path, key, body string) *httptest.ResponseRecorder {
+	req := httptest.NewRequest(method, path, strings.NewReader(body))
+	req.Header.Set("Content-Type", "application/json")
+	if key != "" {
+		req.Header.Set(idempotencyKeyHeader, key)
+	}
+	rec := httptest.NewRecorder()
+	r.ServeHTTP(rec, req)
+
+	return rec
+}
+
+// errorCode возвращает error_code из тела ответа с ошибкой
+func errorCode(t *testing.T, rec *httptest.ResponseRecorder) string {
+	t.Helper()
+
+	var resp ErrorResponse
+	if err := json.Unmarshal(rec.Body.Bytes(), &resp); err != nil {
+		t.Fatalf("Expected error response, got %q: %v", rec.Body.String(), err)
+	}
+
+	return resp.ErrorCode
+}
+
+func TestIdempotencyReplay(t *testing.T) {
+	store := memory.NewStore()
+	r := newTestRouter(store)
+
+	// Повтор запроса с тем же ключом возвращает сохраненный ответ и не создает второй склад
+	body := `{"name":"north","is_available":true}`
+	first := doRequest(r, http.MethodPost, "/api/v1/warehouses", "create-north", body)
+	if first.Code != http.StatusCreated {
+		t.Fatalf("Expected status 201, got %d: %s", first.Code, first.Body.String())
+	}
+	second := doRequest(r, http.MethodPost, "/api/v1/warehouses", "create-north", body)
+	if second.Code != http.StatusCreated || second.Body.String() != first.Body.String() {
+		t.Errorf("Expected replayed response %d %q, got %d %q", first.Code, first.Body.String(), second.Code, second.Body.String())
+	}
+	if second.Header().Get(idempotencyReplayedHeader) != "true" {
+		t.Errorf("Expected %s header on replay", idempotencyReplayedHeader)
+	}
+
+	warehouses, err := controller.ListWarehouses(store)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(warehouses) != 1 {
+		t.Errorf("Expected 1 warehouse, got %d", len(warehouses))
+	}
+
+	// Тот же ключ с другим телом запроса
+	rec := doRequest(r, http.MethodPost, "/api/v1/warehouses", "create-north", `{"name":"south"}`)
+	if rec.Code != http.StatusUnprocessableEntity || errorCode(t, rec) != controller.ErrIdempotencyKeyReused.Code {
+		t.Errorf("Expected 422 %s, got %d %s", controller.ErrIdempotencyKeyReused.Code, rec.Code, rec.Body.String())
+	}
+}
+
+func TestIdempotencyInProgress(t *testing.T) {
+	store := memory.NewStore()
+	r := newTestRouter(store)
+
+	// Ключ закреплен за запросом, который еще выполняется
+	body := `{"name":"north"}`
+	hash := sha256.Sum256([]byte(body))
+	_, err := store.Idempotency().Claim(&controller.IdempotentRequest{
+		Key:         "in-progress",
+		Method:      http.MethodPost,
+		Path:        "/api/v1/warehouses",
+		RequestHash: hex.EncodeToString(hash[:]),
+	}, time.Hour, time.Hour)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	rec := doRequest(r, http.MethodPost, "/api/v1/warehouses", "in-progress", body)
+	if rec.Code != http.StatusConflict || errorCode(t, rec) != controller.ErrIdempotencyKeyInProgress.Code {
+		t.Errorf("Expected 409 %s, got %d %s", controller.ErrIdempotencyKeyInProgress.Code, rec.Code, rec.Body.String())
+	}
+}
+
+func TestIdempotencyReleasesKeyOnServerError(t *testing.T) {
+	r := newTestRouter(memory.NewStore())
+
+	// Первый запрос завершается ответом 5xx, второй паникой, третий выполняется
+	calls := 0
+	r.POST("/flaky", func(c *gin.Context) {
+		calls++
+		switch calls {
+		case 1:
+			c.JSON(http.StatusInternalServerError, ErrorResponse{Code: http.StatusInternalServerError})
+		case 2:
+			panic("handler failed")
+		default:
+			c.JSON(http.StatusCreated, gin.H{"calls": calls})
+		}
+	})
+
+	for _, want := range []int{http.StatusInternalServerError, http.StatusInternalServerError, http.StatusCreated} {
+		rec := doRequest(r, http.MethodPost, "/flaky", "flaky", `{}`)
+		if rec.Code != want {
+			t.Fatalf("Expected status %d, got %d: %s", want, rec.Code, rec.Body.String())
+		}
+	}
+
+	// Успешный ответ сохранен и повторяется без вызова обработчика
+	rec := doRequest(r, http.MethodPost, "/flaky", "flaky", `{}`)
+	if rec.Code != http.StatusCreated || calls != 3 {
+		t.Errorf("Expected replayed 201 after 3 calls, got %d after %d calls", rec.Code, calls)
+	}
+}
+
+func TestIdempotencyKeyLength(t *testing.T) {
+	r := newTestRouter(memory.NewStore())
+
+	rec := doRequest(r, http.MethodPost, "/api/v1/warehouses", strings.Repeat("k", maxIdempotencyKeyLength+1), `{"name":"north"}`)
+	if rec.Code != http.StatusBadRequest {
+		t.Errorf("Expected status 400 for too long key, got %d", rec.Code)
+	}
+
+	rec = doRequest(r, http.MethodPost, "/api/v1/warehouses", strings.Repeat("k", maxIdempotencyKeyLength), `{"name":"north"}`)
+	if rec.Code != http.StatusCreated {
+		t.Errorf("Expected status 201 for key of maximum length, got %d: %s", rec.Code, rec.Body.String())
+	}
+}
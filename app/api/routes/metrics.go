@@ -0,0 +1,44 @@
+package route
+
+import (
+	"net/http"
+	"strconv"
+	"time"
+
+	"lamoda-test/pkg/metrics"
+
+	"github.com/gin-gonic/gin"
+	"github.com/prometheus/client_golang/prometheus/promhttp"
+)
+
+// unmatchedRoute метка запросов, не попавших ни в один маршрут, чтобы случайные пути не раздували число серий
+const unmatchedRoute = "unmatched"
+
+// instrument считает запросы и время их обработки по шаблону маршрута, а не по фактическому пути
+func instrument() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		start := time.Now()
+		completed := false
+		// Запрос учитывается и при панике в обработчике: ее перехватывает gin.Recovery и отвечает 500 уже после нас
+		defer func() {
+			status := c.Writer.Status()
+			if !completed && !c.Writer.Written() {
+				status = http.StatusInternalServerError
+			}
+			route := c.FullPath()
+			if route == "" {
+				route = unmatchedRoute
+			}
+			metrics.HTTPRequests.WithLabelValues(route, c.Request.Method, strconv.Itoa(status)).Inc()
+			metrics.HTTPRequestDuration.WithLabelValues(route, c.Request.Method).Observe(time.Since(start).Seconds())
+		}()
+
+		c.Next()
+		completed = true
+	}
+}
+
+// metricsRoutes регистрирует выдачу метрик Prometheus
+func metricsRoutes(r *gin.Engine) {
+	r.GET("/metrics", gin.WrapH(promhttp.HandlerFor(metrics.Registry, promhttp.HandlerOpts{})))
+}
@@ -0,0 +1,47 @@
+package route
+
+import (
+	"net/http"
+	"strconv"
+	"testing"
+
+	"lamoda-test/pkg/client/memory"
+	"lamoda-test/pkg/metrics"
+
+	"github.com/gin-gonic/gin"
+	"github.com/prometheus/client_golang/prometheus/testutil"
+)
+
+func TestInstrumentLabels(t *testing.T) {
+	r := newTestRouter(memory.NewStore())
+	// Паника перехватывается gin.Recovery, запрос должен попасть в метрики со статусом 500
+	r.GET("/boom", func(c *gin.Context) {
+		panic("handler failed")
+	})
+
+	tests := []struct {
+		name   string
+		path   string
+		route  string
+		status int
+	}{
+		{"route template", "/api/v1/warehouses/42", "/api/v1/warehouses/:id", http.StatusNotFound},
+		{"unmatched", "/no/such/path", unmatchedRoute, http.StatusNotFound},
+		{"panic", "/boom", "/boom", http.StatusInternalServerError},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			counter := metrics.HTTPRequests.WithLabelValues(tt.route, http.MethodGet, strconv.Itoa(tt.status))
+			before := testutil.ToFloat64(counter)
+
+			rec := doRequest(r, http.MethodGet, tt.path, "", "")
+			if rec.Code != tt.status {
+				t.Fatalf("Expected status %d, got %d", tt.status, rec.Code)
+			}
+			if got := testutil.ToFloat64(counter) - before; got != 1 {
+				t.Errorf("Expected 1 request with route %q and status %d, got %v", tt.route, tt.status, got)
+			}
+		})
+	}
+}
@@ -0,0 +1,29 @@
+package route
+
+import (
+	"net/http"
+
+	"lamoda-test/api/controller"
+
+	"github.com/gin-gonic/gin"
+)
+
+// movementRoutes регистрирует обработчики журнала движения остатков
+func movementRoutes(r routes, store controller.Store) {
+	// Журнал движения остатков по продукту, складу и периоду
+	r.handle(http.MethodGet, "/stock-movements", "/stock-movements", func(c *gin.Context) {
+		var filter controller.MovementFilter
+		if err := c.ShouldBindQuery(&filter); err != nil {
+			abortWithBadRequest(c, "invalid query parameters")
+			return
+		}
+
+		page, err := controller.ListStockMovements(store, &filter)
+		if err != nil {
+			abortWithError(c, err)
+			return
+		}
+
+		c.JSON(http.StatusOK, page)
+	})
+}
@@ -0,0 +1,111 @@
+package route
+
+import (
+	"net/http"
+
+	"lamoda-test/api/controller"
+
+	"github.com/gin-gonic/gin"
+)
+
+// productRoutes регистрирует обработчики продуктов
+func productRoutes(r routes, store controller.Store) {
+	// Обработчик для создания нового продукта на заданном складе
+	r.handle(http.MethodPost, "/products", "/create-product", func(c *gin.Context) {
+		var p controller.Product
+		err := c.BindJSON(&p)
+		if err != nil {
+			abortWithBadRequest(c, "invalid product data")
+			return
+		}
+
+		err = controller.CreateProduct(store, &p)
+		if err != nil {
+			abortWithError(c, err)
+			return
+		}
+
+		c.JSON(http.StatusCreated, gin.H{"id": p.ID})
+	})
+
+	// Удаление продукта
+	r.handle(http.MethodDelete, "/products/:id", "/delete-product/:id", func(c *gin.Context) {
+		id, ok := pathID(c, "invalid product ID")
+		if !ok {
+			return
+		}
+
+		if err := controller.DeleteProduct(store, id); err != nil {
+			abortWithError(c, err)
+			return
+		}
+
+		c.Status(http.StatusNoContent)
+	})
+
+	// Список продуктов с фильтрами, сортировкой и пагинацией
+	r.handle(http.MethodGet, "/products", "/products", func(c *gin.Context) {
+		var filter controller.ProductFilter
+		if err := c.ShouldBindQuery(&filter); err != nil {
+			abortWithBadRequest(c, "invalid query parameters")
+			return
+		}
+
+		page, err := controller.ListProducts(store, &filter)
+		if err != nil {
+			abortWithError(c, err)
+			return
+		}
+
+		c.JSON(http.StatusOK, page)
+	})
+
+	// Получение продукта по ID
+	r.handle(http.MethodGet, "/products/:id", "/products/:id", func(c *gin.Context) {
+		id, ok := pathID(c, "invalid product ID")
+		if !ok {
+			return
+		}
+
+		p, err := controller.GetProduct(store, id)
+		if err != nil {
+			abortWithError(c, err)
+			return
+		}
+
+		c.JSON(http.StatusOK, p)
+	})
+
+	// Получение продукта по коду на всех складах
+	r.handle(http.MethodGet, "/products/by-code/:code", "/products/by-code/:code", func(c *gin.Context) {
+		products, err := controller.GetProductsByCode(store, c.Param("code"))
+		if err != nil {
+			abortWithError(c, err)
+			return
+		}
+
+		c.JSON(http.StatusOK, products)
+	})
+
+	// Частичное обновление продукта
+	r.handle(http.MethodPatch, "/products/:id", "/products/:id", func(c *gin.Context) {
+		id, ok := pathID(c, "invalid product ID")
+		if !ok {
+			return
+		}
+
+		var patch controller.ProductPatch
+		if err := c.ShouldBindJSON(&patch); err != nil {
+			abortWithBadRequest(c, "invalid request body")
+			return
+		}
+
+		p, err := controller.UpdateProduct(store, id, &patch)
+		if err != nil {
+			abortWithError(c, err)
+			return
+		}
+
+		c.JSON(http.StatusOK, p)
+	})
+}
@@ -0,0 +1,45 @@
+package route
+
+import (
+	"net/http"
+
+	"lamoda-test/api/controller"
+
+	"github.com/gin-gonic/gin"
+)
+
+// receiptRoutes регистрирует обработчики приемки товара
+func receiptRoutes(r routes, store controller.Store) {
+	// Приемка товара от поставщика
+	r.handle(http.MethodPost, "/receipts", "/receipts", func(c *gin.Context) {
+		var receipt controller.Receipt
+		if err := c.ShouldBindJSON(&receipt); err != nil {
+			abortWithBadRequest(c, "invalid request body")
+			return
+		}
+
+		err := controller.CreateReceipt(store, &receipt)
+		if err != nil {
+			abortWithError(c, err)
+			return
+		}
+
+		c.JSON(http.StatusCreated, receipt)
+	})
+
+	// Получение приемки
+	r.handle(http.MethodGet, "/receipts/:id", "/receipts/:id", func(c *gin.Context) {
+		id, ok := pathID(c, "invalid receipt ID")
+		if !ok {
+			return
+		}
+
+		receipt, err := controller.GetReceipt(store, id)
+		if err != nil {
+			abortWithError(c, err)
+			return
+		}
+
+		c.JSON(http.StatusOK, receipt)
+	})
+}
@@ -0,0 +1,129 @@
+package route
+
+import (
+	"net/http"
+	"time"
+
+	"lamoda-test/api/controller"
+
+	"github.com/gin-gonic/gin"
+)
+
+// reserveRequest тело запроса на резервирование.
+// TTLSeconds задает срок действия резервирования, 0 - бессрочно.
+// Strategy задает распределение по складам, для nearest обязателен Location.
+// Partial включает частичное резервирование корзины
+type reserveRequest struct {
+	OrderRef    string                       `json:"order_ref"`
+	WarehouseID int                          `json:"warehouse_id"`
+	Lines       []controller.ReservationLine `json:"lines"`
+	TTLSeconds  int                          `json:"ttl_seconds"`
+	Strategy    string                       `json:"strategy"`
+	Location    *controller.Location         `json:"location"`
+	Partial     bool                         `json:"partial"`
+}
+
+// releaseRequest тело запроса на снятие резерва по кодам
+type releaseRequest struct {
+	Lines []controller.ReservationLine `json:"lines"`
+}
+
+// reservationRoutes регистрирует обработчики резервирований
+func reservationRoutes(r routes, store controller.Store) {
+	// Резервирование продуктов
+	r.handle(http.MethodPost, "/reservations", "/reserve-products", func(c *gin.Context) {
+		var req reserveRequest
+		if err := c.ShouldBindJSON(&req); err != nil || req.TTLSeconds < 0 {
+			abortWithBadRequest(c, "invalid request body")
+			return
+		}
+
+		strategy, err := controller.NewAllocationStrategy(req.Strategy, req.Location)
+		if err != nil {
+			abortWithError(c, err)
+			return
+		}
+
+		res := controller.Reservation{OrderRef: req.OrderRef, WarehouseID: req.WarehouseID, Lines: req.Lines, Strategy: strategy, Partial: req.Partial}
+		if req.TTLSeconds > 0 {
+			expiresAt := time.Now().Add(time.Duration(req.TTLSeconds) * time.Second)
+			res.ExpiresAt = &expiresAt
+		}
+
+		err = controller.ReserveProducts(store, &res)
+		if err != nil {
+			// Итоги строк нужны клиенту и тогда, когда не зарезервировано ничего
+			resp := errorResponse(c, err)
+			resp.Results = res.Results
+			c.AbortWithStatusJSON(resp.Code, resp)
+			return
+		}
+
+		c.JSON(http.StatusCreated, res)
+	})
+
+	// Отмена резервирования продуктов по кодам
+	r.handle(http.MethodPost, "/releases", "/release-products", func(c *gin.Context) {
+		var req releaseRequest
+		if err := c.ShouldBindJSON(&req); err != nil {
+			abortWithBadRequest(c, "invalid request body")
+			return
+		}
+
+		err := controller.ReleaseProducts(store, req.Lines)
+		if err != nil {
+			abortWithError(c, err)
+			return
+		}
+
+		c.Status(http.StatusOK)
+	})
+
+	// Получение резервирования
+	r.handle(http.MethodGet, "/reservations/:id", "/reservations/:id", func(c *gin.Context) {
+		id, ok := pathID(c, "invalid reservation ID")
+		if !ok {
+			return
+		}
+
+		res, err := controller.GetReservation(store, id)
+		if err != nil {
+			abortWithError(c, err)
+			return
+		}
+
+		c.JSON(http.StatusOK, res)
+	})
+
+	// Снятие резерва
+	r.handle(http.MethodPost, "/reservations/:id/release", "/reservations/:id/release", func(c *gin.Context) {
+		id, ok := pathID(c, "invalid reservation ID")
+		if !ok {
+			return
+		}
+
+		res, err := controller.ReleaseReservation(store, id)
+		if err != nil {
+			abortWithError(c, err)
+			return
+		}
+
+		c.JSON(http.StatusOK, res)
+	})
+
+	// Выдача зарезервированных продуктов
+	r.handle(http.MethodPost, "/reservations/:id/fulfill", "/reservations/:id/fulfill", func(c *gin.Context) {
+		id, ok := pathID(c, "invalid reservation ID")
+		if !ok {
+			return
+		}
+
+		res, err := controller.FulfillReservation(store, id)
+		if err != nil {
+			abortWithError(c, err)
+			return
+		}
+
+		c.JSON(http.StatusOK, res)
+	})
+}
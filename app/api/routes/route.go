@@ -1,10 +1,9 @@
 package route
 
 import (
-	"database/sql"
-	"fmt"
 	"net/http"
 	"strconv"
+	"time"
 
 	"lamoda-test/api/controller"
 
@@ -13,145 +12,122 @@ import (
 	httpSwagger "github.com/swaggo/http-swagger"
 )
 
-// ErrorResponse структура возвращенной ошибки
+// ErrorResponse структура возвращенной ошибки.
+// Code - HTTP статус, ErrorCode - стабильный машиночитаемый код ошибки,
+// ProductCode и WarehouseID указывают на продукт или склад, из-за которого запрос не выполнен,
+// Fields - поля запроса, не прошедшие проверку, Results - итоги строк при частичном резервировании
 type ErrorResponse struct {
-	Code    int    `json:"code"`
-	Message string `json:"message"`
+	Code        int                     `json:"code"`
+	ErrorCode   string                  `json:"error_code"`
+	Message     string                  `json:"message"`
+	ProductCode string                  `json:"product_code,omitempty"`
+	WarehouseID int                     `json:"warehouse_id,omitempty"`
+	Fields      []controller.FieldError `json:"fields,omitempty"`
+	Results     []controller.LineResult `json:"results,omitempty"`
 }
 
-func NewRouter(db *sql.DB) *gin.Engine {
+// errorStatus HTTP статусы для видов ошибок предметной области
+var errorStatus = map[controller.ErrorKind]int{
+	controller.KindNotFound:   http.StatusNotFound,
+	controller.KindOutOfStock: http.StatusConflict,
+	controller.KindConflict:   http.StatusConflict,
+	controller.KindValidation: http.StatusUnprocessableEntity,
+}
+
+// NewRouter создает роутер, ответы на изменяющие запросы с Idempotency-Key хранятся idempotencyTTL,
+// пробы оркестратора отвечают по состоянию health
+func NewRouter(store controller.Store, health *Health, idempotencyTTL time.Duration) *gin.Engine {
 	// Инициализируем роутер gin
 	r := gin.Default()
+	// Устаревшие пути без версии и их замены в /api/v1, заполняются при регистрации обработчиков
+	successors := map[string]string{}
+
+	// Метрики снимаются до идемпотентности, чтобы учитывать и повторы сохраненных ответов
+	r.Use(instrument())
+	r.Use(deprecation(successors))
+	r.Use(idempotency(store, idempotencyTTL))
 
 	r.GET("/swagger/*any", gin.WrapH(httpSwagger.Handler()))
 	r.GET("/swagger", func(c *gin.Context) {
 		c.Redirect(http.StatusMovedPermanently, "/swagger/index.html")
 	})
 
-	// Обработчик для создания нового склада
-	r.POST("/create-warehouse", func(c *gin.Context) {
-		// Считываем данные склада из тела запроса
-		var w controller.Warehouse
-		err := c.BindJSON(&w)
-		if err != nil {
-			c.AbortWithStatusJSON(http.StatusBadRequest, gin.H{"error": "Invalid warehouse data"})
-			return
-		}
-
-		// Создаем новый склад в базе данных
-		err = controller.CreateWarehouse(db, &w)
-		if err != nil {
-			c.AbortWithStatusJSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
-			return
-		}
-
-		// Отправляем ответ с ID нового склада
-		c.JSON(http.StatusCreated, gin.H{"id": w.ID})
-	})
+	// Пробы живости, запуска и готовности
+	healthRoutes(r, health)
 
-	// Обработчик для создания нового продукта на заданном складе
-	r.POST("/create-product", func(c *gin.Context) {
-		var p controller.Product
-		err := c.BindJSON(&p)
-		if err != nil {
-			c.AbortWithStatusJSON(http.StatusBadRequest, gin.H{"error": "Invalid product data"})
-			return
-		}
-
-		err = controller.CreateProduct(db, &p)
-		if err != nil {
-			c.AbortWithStatusJSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
-			return
-		}
-
-		c.JSON(http.StatusCreated, gin.H{"id": p.ID})
-	})
+	// Метрики Prometheus
+	metricsRoutes(r)
 
-	// Удаление продукта
-	r.DELETE("/delete-product/:id", func(c *gin.Context) {
-		id, err := strconv.Atoi(c.Param("id"))
-		if err != nil {
-			c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid product ID"})
-			return
-		}
+	// Ресурсы API регистрируются в /api/v1 и по старым путям без версии.
+	// Группа создается после подключения middleware, иначе они не применятся к ее маршрутам
+	api := routes{v1: r.Group(apiV1), legacy: r, successors: successors}
 
-		if err := controller.DeleteProduct(db, id); err != nil {
-			c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
-			return
-		}
+	// Склады
+	warehouseRoutes(api, store)
 
-		c.Status(http.StatusNoContent)
-	})
+	// Продукты
+	productRoutes(api, store)
 
-	// Резервирование продуктов
-	r.POST("/reserve-products", func(c *gin.Context) {
-		var productCodes []string
-		if err := c.ShouldBindJSON(&productCodes); err != nil {
-			c.JSON(http.StatusBadRequest, ErrorResponse{
-				Code:    http.StatusBadRequest,
-				Message: "invalid request body",
-			})
-			return
-		}
-
-		err := controller.ReserveProducts(db, productCodes)
-		if err != nil {
-			c.JSON(http.StatusInternalServerError, ErrorResponse{
-				Code:    http.StatusInternalServerError,
-				Message: err.Error(),
-			})
-			return
-		}
-
-		c.Status(http.StatusOK)
-	})
+	// Резервирования
+	reservationRoutes(api, store)
 
-	// Отмена резервирования продуктов
-	r.POST("/release-products", func(c *gin.Context) {
-		var productCodes []string
-		if err := c.ShouldBindJSON(&productCodes); err != nil {
-			c.JSON(http.StatusBadRequest, ErrorResponse{
-				Code:    http.StatusBadRequest,
-				Message: "invalid request body",
-			})
-			return
-		}
-
-		err := controller.ReleaseProducts(db, productCodes)
-		if err != nil {
-			c.JSON(http.StatusInternalServerError, ErrorResponse{
-				Code:    http.StatusInternalServerError,
-				Message: err.Error(),
-			})
-			return
-		}
-
-		c.Status(http.StatusOK)
-	})
+	// Корректировки остатка
+	adjustmentRoutes(api, store)
 
-	// Получения оставшегося количества продуктов на складе
-	r.GET("/remaining-products/:warehouseID", func(c *gin.Context) {
-		warehouseID := c.Param("warehouseID")
-		var id int
-		if _, err := fmt.Sscan(warehouseID, &id); err != nil {
-			c.JSON(http.StatusBadRequest, ErrorResponse{
-				Code:    http.StatusBadRequest,
-				Message: "invalid warehouse ID",
-			})
-			return
-		}
-
-		products, err := controller.GetRemainingProducts(db, id)
-		if err != nil {
-			c.JSON(http.StatusInternalServerError, ErrorResponse{
-				Code:    http.StatusInternalServerError,
-				Message: err.Error(),
-			})
-			return
-		}
-
-		c.JSON(http.StatusOK, products)
-	})
+	// Журнал движения остатков
+	movementRoutes(api, store)
+
+	// Перемещения между складами
+	transferRoutes(api, store)
+
+	// Приемка товара
+	receiptRoutes(api, store)
 
 	return r
 }
+
+// pathID считывает ID из пути, при ошибке отвечает 400 с сообщением message
+func pathID(c *gin.Context, message string) (int, bool) {
+	id, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		abortWithBadRequest(c, message)
+		return 0, false
+	}
+
+	return id, true
+}
+
+// abortWithBadRequest отвечает 400 на запрос, который не удалось разобрать
+func abortWithBadRequest(c *gin.Context, message string) {
+	c.AbortWithStatusJSON(http.StatusBadRequest, ErrorResponse{
+		Code:      http.StatusBadRequest,
+		ErrorCode: "invalid_request",
+		Message:   message,
+	})
+}
+
+// abortWithError отправляет ответ с ошибкой контроллера и подходящим HTTP статусом
+func abortWithError(c *gin.Context, err error) {
+	resp := errorResponse(c, err)
+	c.AbortWithStatusJSON(resp.Code, resp)
+}
+
+// errorResponse описывает ошибку контроллера для ответа.
+// Текст непредвиденных ошибок попадает только в лог, клиент получает код internal
+func errorResponse(c *gin.Context, err error) ErrorResponse {
+	e := controller.AsError(err)
+	code, ok := errorStatus[e.Kind]
+	if !ok {
+		code = http.StatusInternalServerError
+		_ = c.Error(err)
+	}
+
+	return ErrorResponse{
+		Code:        code,
+		ErrorCode:   e.Code,
+		Message:     e.Message,
+		ProductCode: e.ProductCode,
+		WarehouseID: e.WarehouseID,
+		Fields:      e.Fields,
+	}
+}
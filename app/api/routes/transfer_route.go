@@ -0,0 +1,29 @@
+package route
+
+import (
+	"net/http"
+
+	"lamoda-test/api/controller"
+
+	"github.com/gin-gonic/gin"
+)
+
+// transferRoutes регистрирует обработчики перемещений между складами
+func transferRoutes(r routes, store controller.Store) {
+	// Перемещение продукта между складами
+	r.handle(http.MethodPost, "/transfers", "/transfers", func(c *gin.Context) {
+		var t controller.Transfer
+		if err := c.ShouldBindJSON(&t); err != nil {
+			abortWithBadRequest(c, "invalid request body")
+			return
+		}
+
+		err := controller.CreateTransfer(store, &t)
+		if err != nil {
+			abortWithError(c, err)
+			return
+		}
+
+		c.JSON(http.StatusCreated, t)
+	})
+}
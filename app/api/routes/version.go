@@ -0,0 +1,48 @@
+package route
+
+import (
+	"net/url"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+)
+
+// apiV1 префикс текущей версии API
+const apiV1 = "/api/v1"
+
+// routes регистрирует обработчики в /api/v1 и по устаревшим путям без версии.
+// successors хранит для каждого устаревшего пути его замену в /api/v1
+type routes struct {
+	v1         gin.IRouter
+	legacy     gin.IRouter
+	successors map[string]string
+}
+
+// handle регистрирует обработчик по пути path в /api/v1 и по устаревшему пути legacyPath.
+// Параметры в обоих путях должны называться одинаково
+func (r routes) handle(method, path, legacyPath string, h gin.HandlerFunc) {
+	r.v1.Handle(method, path, h)
+	r.legacy.Handle(method, legacyPath, h)
+	r.successors[method+" "+legacyPath] = apiV1 + path
+}
+
+// deprecation помечает ответы на устаревшие пути заголовком Deprecation и ссылкой на путь в /api/v1.
+// Стоит до идемпотентности, чтобы заголовки были и у повторов сохраненных ответов
+func deprecation(successors map[string]string) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		successor, ok := successors[c.Request.Method+" "+c.FullPath()]
+		if !ok {
+			c.Next()
+			return
+		}
+
+		// Подставляем значения параметров запроса в путь замены, экранируя их для ссылки
+		for _, p := range c.Params {
+			successor = strings.Replace(successor, ":"+p.Key, url.PathEscape(p.Value), 1)
+		}
+		c.Header("Deprecation", "true")
+		c.Header("Link", "<"+successor+`>; rel="successor-version"`)
+
+		c.Next()
+	}
+}
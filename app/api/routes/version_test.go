@@ -0,0 +1,50 @@
+package route
+
+import (
+	"net/http"
+	"testing"
+
+	"lamoda-test/pkg/client/memory"
+)
+
+func TestDeprecationHeaders(t *testing.T) {
+	r := newTestRouter(memory.NewStore())
+
+	tests := []struct {
+		name     string
+		method   string
+		path     string
+		body     string
+		wantCode int
+		wantLink string
+	}{
+		{"legacy", http.MethodPost, "/create-warehouse", `{"name":"north"}`, http.StatusCreated, `</api/v1/warehouses>; rel="successor-version"`},
+		{"legacy with param", http.MethodGet, "/products/by-code/a%20b%3F", "", http.StatusNotFound, `</api/v1/products/by-code/a%20b%3F>; rel="successor-version"`},
+		{"v1", http.MethodPost, "/api/v1/warehouses", `{"name":"south"}`, http.StatusCreated, ""},
+		{"v1 with param", http.MethodGet, "/api/v1/warehouses/1", "", http.StatusOK, ""},
+	}
+
+	// Склад 1 для последнего случая создается запросом по устаревшему пути
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			rec := doRequest(r, tt.method, tt.path, "", tt.body)
+			if rec.Code != tt.wantCode {
+				t.Fatalf("Expected status %d, got %d: %s", tt.wantCode, rec.Code, rec.Body.String())
+			}
+
+			deprecated := rec.Header().Get("Deprecation")
+			if tt.wantLink == "" {
+				if deprecated != "" || rec.Header().Get("Link") != "" {
+					t.Errorf("Expected no deprecation headers on %s, got %q %q", tt.path, deprecated, rec.Header().Get("Link"))
+				}
+				return
+			}
+			if deprecated != "true" {
+				t.Errorf("Expected Deprecation: true on %s, got %q", tt.path, deprecated)
+			}
+			if link := rec.Header().Get("Link"); link != tt.wantLink {
+				t.Errorf("Expected Link %q, got %q", tt.wantLink, link)
+			}
+		})
+	}
+}
@@ -0,0 +1,119 @@
+package route
+
+import (
+	"net/http"
+
+	"lamoda-test/api/controller"
+
+	"github.com/gin-gonic/gin"
+)
+
+// warehouseRoutes регистрирует обработчики складов
+func warehouseRoutes(r routes, store controller.Store) {
+	// Обработчик для создания нового склада
+	r.handle(http.MethodPost, "/warehouses", "/create-warehouse", func(c *gin.Context) {
+		// Считываем данные склада из тела запроса
+		var w controller.Warehouse
+		err := c.BindJSON(&w)
+		if err != nil {
+			abortWithBadRequest(c, "invalid warehouse data")
+			return
+		}
+
+		// Создаем новый склад в базе данных
+		err = controller.CreateWarehouse(store, &w)
+		if err != nil {
+			abortWithError(c, err)
+			return
+		}
+
+		// Отправляем ответ с ID нового склада
+		c.JSON(http.StatusCreated, gin.H{"id": w.ID})
+	})
+
+	// Список складов
+	r.handle(http.MethodGet, "/warehouses", "/warehouses", func(c *gin.Context) {
+		warehouses, err := controller.ListWarehouses(store)
+		if err != nil {
+			abortWithError(c, err)
+			return
+		}
+
+		c.JSON(http.StatusOK, warehouses)
+	})
+
+	// Получение склада
+	r.handle(http.MethodGet, "/warehouses/:id", "/warehouses/:id", func(c *gin.Context) {
+		id, ok := pathID(c, "invalid warehouse ID")
+		if !ok {
+			return
+		}
+
+		w, err := controller.GetWarehouse(store, id)
+		if err != nil {
+			abortWithError(c, err)
+			return
+		}
+
+		c.JSON(http.StatusOK, w)
+	})
+
+	// Частичное обновление склада, в том числе смена доступности
+	r.handle(http.MethodPatch, "/warehouses/:id", "/warehouses/:id", func(c *gin.Context) {
+		id, ok := pathID(c, "invalid warehouse ID")
+		if !ok {
+			return
+		}
+
+		var patch controller.WarehousePatch
+		if err := c.ShouldBindJSON(&patch); err != nil {
+			abortWithBadRequest(c, "invalid request body")
+			return
+		}
+
+		w, err := controller.UpdateWarehouse(store, id, &patch)
+		if err != nil {
+			abortWithError(c, err)
+			return
+		}
+
+		c.JSON(http.StatusOK, w)
+	})
+
+	// Удаление склада
+	r.handle(http.MethodDelete, "/warehouses/:id", "/warehouses/:id", func(c *gin.Context) {
+		id, ok := pathID(c, "invalid warehouse ID")
+		if !ok {
+			return
+		}
+
+		if err := controller.DeleteWarehouse(store, id); err != nil {
+			abortWithError(c, err)
+			return
+		}
+
+		c.Status(http.StatusNoContent)
+	})
+
+	// Получения оставшегося количества продуктов на складе
+	r.handle(http.MethodGet, "/warehouses/:id/stock", "/remaining-products/:id", func(c *gin.Context) {
+		id, ok := pathID(c, "invalid warehouse ID")
+		if !ok {
+			return
+		}
+
+		var filter controller.ProductFilter
+		if err := c.ShouldBindQuery(&filter); err != nil {
+			abortWithBadRequest(c, "invalid query parameters")
+			return
+		}
+
+		page, err := controller.GetRemainingProducts(store, id, &filter)
+		if err != nil {
+			abortWithError(c, err)
+			return
+		}
+
+		c.JSON(http.StatusOK, page)
+	})
+}
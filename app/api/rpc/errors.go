@@ -0,0 +1,82 @@
+package rpc
+
+import (
+	"strconv"
+
+	"lamoda-test/api/controller"
+	"lamoda-test/api/rpc/inventorypb"
+
+	"google.golang.org/genproto/googleapis/rpc/errdetails"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+// errorDomain домен ошибок в google.rpc.ErrorInfo
+const errorDomain = "inventory.lamoda-test"
+
+// errorCodes коды gRPC для видов ошибок предметной области, как errorStatus в HTTP API
+var errorCodes = map[controller.ErrorKind]codes.Code{
+	controller.KindNotFound:   codes.NotFound,
+	controller.KindOutOfStock: codes.FailedPrecondition,
+	controller.KindConflict:   codes.FailedPrecondition,
+	controller.KindValidation: codes.InvalidArgument,
+}
+
+// statusError переводит ошибку контроллера в статус gRPC.
+// Код ошибки, продукт и склад передаются в ErrorInfo, поля с ошибками проверки - в BadRequest.
+// Текст непредвиденных ошибок клиенту не передается
+func statusError(err error) error {
+	e := controller.AsError(err)
+	code, ok := errorCodes[e.Kind]
+	if !ok {
+		code = codes.Internal
+	}
+
+	info := &errdetails.ErrorInfo{Reason: e.Code, Domain: errorDomain, Metadata: map[string]string{}}
+	if e.ProductCode != "" {
+		info.Metadata["product_code"] = e.ProductCode
+	}
+	if e.WarehouseID != 0 {
+		info.Metadata["warehouse_id"] = strconv.Itoa(e.WarehouseID)
+	}
+
+	st := status.New(code, e.Message)
+	withDetails, detailsErr := st.WithDetails(info)
+	if detailsErr != nil {
+		return st.Err()
+	}
+	if len(e.Fields) > 0 {
+		violations := make([]*errdetails.BadRequest_FieldViolation, 0, len(e.Fields))
+		for _, f := range e.Fields {
+			violations = append(violations, &errdetails.BadRequest_FieldViolation{Field: f.Field, Description: f.Message})
+		}
+		withDetails, detailsErr = withDetails.WithDetails(&errdetails.BadRequest{FieldViolations: violations})
+		if detailsErr != nil {
+			return st.Err()
+		}
+	}
+
+	return withDetails.Err()
+}
+
+// withResults добавляет к статусу итоги строк частичного резервирования, как results в ответе HTTP API
+func withResults(err error, results []controller.LineResult) error {
+	st := status.Convert(err)
+	withDetails, detailsErr := st.WithDetails(&inventorypb.ReservationFailure{Results: toLineResults(results)})
+	if detailsErr != nil {
+		return err
+	}
+
+	return withDetails.Err()
+}
+
+// invalidArgument ошибка запроса, который не удалось разобрать, как abortWithBadRequest в HTTP API
+func invalidArgument(message string) error {
+	st := status.New(codes.InvalidArgument, message)
+	withDetails, err := st.WithDetails(&errdetails.ErrorInfo{Reason: "invalid_request", Domain: errorDomain})
+	if err != nil {
+		return st.Err()
+	}
+
+	return withDetails.Err()
+}
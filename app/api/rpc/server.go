@@ -0,0 +1,335 @@
+// Package rpc реализует gRPC API склада поверх той же логики контроллеров, что и HTTP API
+package rpc
+
+import (
+	"context"
+	"time"
+
+	"lamoda-test/api/controller"
+	"lamoda-test/api/rpc/inventorypb"
+	"lamoda-test/pkg/logging"
+
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/health"
+	healthpb "google.golang.org/grpc/health/grpc_health_v1"
+	"google.golang.org/grpc/status"
+)
+
+// Server реализация InventoryService
+type Server struct {
+	inventorypb.UnimplementedInventoryServiceServer
+
+	store controller.Store
+}
+
+// NewServer создает gRPC сервер с InventoryService и стандартной проверкой состояния grpc.health.v1.
+// Логгер из ctx передается в обработчики для записи непредвиденных ошибок
+func NewServer(ctx context.Context, store controller.Store) (*grpc.Server, *health.Server) {
+	logger := logging.GetLogger(ctx)
+	s := grpc.NewServer(grpc.UnaryInterceptor(func(ctx context.Context, req interface{}, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (interface{}, error) {
+		return handler(logging.ContextWithLogger(ctx, logger), req)
+	}))
+
+	inventorypb.RegisterInventoryServiceServer(s, &Server{store: store})
+
+	healthServer := health.NewServer()
+	healthpb.RegisterHealthServer(s, healthServer)
+
+	return s, healthServer
+}
+
+// fail переводит ошибку контроллера в статус gRPC, непредвиденные ошибки пишет в лог
+func fail(ctx context.Context, err error) error {
+	st := statusError(err)
+	if status.Code(st) == codes.Internal {
+		logging.GetLogger(ctx).WithError(err).Error("grpc request failed")
+	}
+
+	return st
+}
+
+func (s *Server) CreateWarehouse(ctx context.Context, req *inventorypb.CreateWarehouseRequest) (*inventorypb.Warehouse, error) {
+	w := controller.Warehouse{
+		Name:        req.GetName(),
+		IsAvailable: req.GetIsAvailable(),
+		Priority:    int(req.GetPriority()),
+	}
+	if l := req.GetLocation(); l != nil {
+		lat, lon := l.GetLatitude(), l.GetLongitude()
+		w.Latitude, w.Longitude = &lat, &lon
+	}
+
+	if err := controller.CreateWarehouse(s.store, &w); err != nil {
+		return nil, fail(ctx, err)
+	}
+
+	return toWarehouse(&w), nil
+}
+
+func (s *Server) ListWarehouses(ctx context.Context, _ *inventorypb.ListWarehousesRequest) (*inventorypb.ListWarehousesResponse, error) {
+	warehouses, err := controller.ListWarehouses(s.store)
+	if err != nil {
+		return nil, fail(ctx, err)
+	}
+
+	resp := &inventorypb.ListWarehousesResponse{Warehouses: make([]*inventorypb.Warehouse, 0, len(warehouses))}
+	for i := range warehouses {
+		resp.Warehouses = append(resp.Warehouses, toWarehouse(&warehouses[i]))
+	}
+
+	return resp, nil
+}
+
+func (s *Server) GetWarehouse(ctx context.Context, req *inventorypb.GetWarehouseRequest) (*inventorypb.Warehouse, error) {
+	w, err := controller.GetWarehouse(s.store, int(req.GetId()))
+	if err != nil {
+		return nil, fail(ctx, err)
+	}
+
+	return toWarehouse(w), nil
+}
+
+func (s *Server) GetWarehouseStock(ctx context.Context, req *inventorypb.GetWarehouseStockRequest) (*inventorypb.ProductPage, error) {
+	filter := controller.ProductFilter{
+		Name:       req.GetName(),
+		Size:       req.GetSize(),
+		OutOfStock: req.GetOutOfStock(),
+		Sort:       req.GetSort(),
+		Limit:      int(req.GetLimit()),
+		Offset:     int(req.GetOffset()),
+	}
+	if req.MinQuantity != nil {
+		min := int(req.GetMinQuantity())
+		filter.MinQuantity = &min
+	}
+	if req.MaxQuantity != nil {
+		max := int(req.GetMaxQuantity())
+		filter.MaxQuantity = &max
+	}
+
+	page, err := controller.GetRemainingProducts(s.store, int(req.GetWarehouseId()), &filter)
+	if err != nil {
+		return nil, fail(ctx, err)
+	}
+
+	resp := &inventorypb.ProductPage{
+		Items:  make([]*inventorypb.Product, 0, len(page.Items)),
+		Total:  int64(page.Total),
+		Limit:  int64(page.Limit),
+		Offset: int64(page.Offset),
+	}
+	for i := range page.Items {
+		resp.Items = append(resp.Items, toProduct(&page.Items[i]))
+	}
+
+	return resp, nil
+}
+
+func (s *Server) CreateProduct(ctx context.Context, req *inventorypb.CreateProductRequest) (*inventorypb.Product, error) {
+	p := controller.Product{
+		Name:        req.GetName(),
+		Size:        req.GetSize(),
+		Code:        req.GetCode(),
+		Quantity:    int(req.GetQuantity()),
+		WarehouseID: int(req.GetWarehouseId()),
+	}
+	if err := controller.CreateProduct(s.store, &p); err != nil {
+		return nil, fail(ctx, err)
+	}
+
+	// У нового продукта нет резерва, весь остаток доступен
+	p.Available = p.Quantity
+
+	return toProduct(&p), nil
+}
+
+func (s *Server) GetProduct(ctx context.Context, req *inventorypb.GetProductRequest) (*inventorypb.Product, error) {
+	p, err := controller.GetProduct(s.store, int(req.GetId()))
+	if err != nil {
+		return nil, fail(ctx, err)
+	}
+
+	return toProduct(p), nil
+}
+
+func (s *Server) GetProductsByCode(ctx context.Context, req *inventorypb.GetProductsByCodeRequest) (*inventorypb.GetProductsByCodeResponse, error) {
+	products, err := controller.GetProductsByCode(s.store, req.GetCode())
+	if err != nil {
+		return nil, fail(ctx, err)
+	}
+
+	resp := &inventorypb.GetProductsByCodeResponse{Products: make([]*inventorypb.Product, 0, len(products))}
+	for i := range products {
+		resp.Products = append(resp.Products, toProduct(&products[i]))
+	}
+
+	return resp, nil
+}
+
+func (s *Server) DeleteProduct(ctx context.Context, req *inventorypb.DeleteProductRequest) (*inventorypb.DeleteProductResponse, error) {
+	if err := controller.DeleteProduct(s.store, int(req.GetId())); err != nil {
+		return nil, fail(ctx, err)
+	}
+
+	return &inventorypb.DeleteProductResponse{}, nil
+}
+
+func (s *Server) ReserveProducts(ctx context.Context, req *inventorypb.ReserveProductsRequest) (*inventorypb.Reservation, error) {
+	if req.GetTtlSeconds() < 0 {
+		return nil, invalidArgument("invalid ttl_seconds")
+	}
+
+	var location *controller.Location
+	if l := req.GetLocation(); l != nil {
+		location = &controller.Location{Latitude: l.GetLatitude(), Longitude: l.GetLongitude()}
+	}
+	strategy, err := controller.NewAllocationStrategy(req.GetStrategy(), location)
+	if err != nil {
+		return nil, fail(ctx, err)
+	}
+
+	r := controller.Reservation{
+		OrderRef:    req.GetOrderRef(),
+		WarehouseID: int(req.GetWarehouseId()),
+		Lines:       fromLines(req.GetLines()),
+		Strategy:    strategy,
+		Partial:     req.GetPartial(),
+	}
+	if req.GetTtlSeconds() > 0 {
+		expiresAt := time.Now().Add(time.Duration(req.GetTtlSeconds()) * time.Second)
+		r.ExpiresAt = &expiresAt
+	}
+
+	if err := controller.ReserveProducts(s.store, &r); err != nil {
+		// Если не зарезервировано ничего, итоги строк передаются в деталях ошибки
+		if len(r.Results) > 0 {
+			return nil, withResults(fail(ctx, err), r.Results)
+		}
+		return nil, fail(ctx, err)
+	}
+
+	return toReservation(&r), nil
+}
+
+func (s *Server) ReleaseProducts(ctx context.Context, req *inventorypb.ReleaseProductsRequest) (*inventorypb.ReleaseProductsResponse, error) {
+	if err := controller.ReleaseProducts(s.store, fromLines(req.GetLines())); err != nil {
+		return nil, fail(ctx, err)
+	}
+
+	return &inventorypb.ReleaseProductsResponse{}, nil
+}
+
+func (s *Server) GetReservation(ctx context.Context, req *inventorypb.GetReservationRequest) (*inventorypb.Reservation, error) {
+	r, err := controller.GetReservation(s.store, int(req.GetId()))
+	if err != nil {
+		return nil, fail(ctx, err)
+	}
+
+	return toReservation(r), nil
+}
+
+func (s *Server) ReleaseReservation(ctx context.Context, req *inventorypb.ReleaseReservationRequest) (*inventorypb.Reservation, error) {
+	r, err := controller.ReleaseReservation(s.store, int(req.GetId()))
+	if err != nil {
+		return nil, fail(ctx, err)
+	}
+
+	return toReservation(r), nil
+}
+
+func (s *Server) FulfillReservation(ctx context.Context, req *inventorypb.FulfillReservationRequest) (*inventorypb.Reservation, error) {
+	r, err := controller.FulfillReservation(s.store, int(req.GetId()))
+	if err != nil {
+		return nil, fail(ctx, err)
+	}
+
+	return toReservation(r), nil
+}
+
+// toWarehouse переводит склад в сообщение gRPC
+func toWarehouse(w *controller.Warehouse) *inventorypb.Warehouse {
+	pb := &inventorypb.Warehouse{
+		Id:          int64(w.ID),
+		Name:        w.Name,
+		IsAvailable: w.IsAvailable,
+		Priority:    int64(w.Priority),
+	}
+	if w.Latitude != nil && w.Longitude != nil {
+		pb.Location = &inventorypb.Location{Latitude: *w.Latitude, Longitude: *w.Longitude}
+	}
+
+	return pb
+}
+
+// toProduct переводит продукт в сообщение gRPC
+func toProduct(p *controller.Product) *inventorypb.Product {
+	return &inventorypb.Product{
+		Id:               int64(p.ID),
+		Name:             p.Name,
+		Size:             p.Size,
+		Code:             p.Code,
+		Quantity:         int64(p.Quantity),
+		ReservedQuantity: int64(p.ReservedQuantity),
+		Available:        int64(p.Available),
+		WarehouseId:      int64(p.WarehouseID),
+	}
+}
+
+// toReservation переводит резервирование в сообщение gRPC, время передается в секундах Unix
+func toReservation(r *controller.Reservation) *inventorypb.Reservation {
+	pb := &inventorypb.Reservation{
+		Id:            int64(r.ID),
+		OrderRef:      r.OrderRef,
+		WarehouseId:   int64(r.WarehouseID),
+		Status:        r.Status,
+		Lines:         make([]*inventorypb.ReservationLine, 0, len(r.Lines)),
+		CreatedAtUnix: r.CreatedAt.Unix(),
+		UpdatedAtUnix: r.UpdatedAt.Unix(),
+	}
+	if r.ExpiresAt != nil {
+		pb.ExpiresAtUnix = r.ExpiresAt.Unix()
+	}
+	for _, l := range r.Lines {
+		pb.Lines = append(pb.Lines, &inventorypb.ReservationLine{
+			ProductId:   int64(l.ProductID),
+			WarehouseId: int64(l.WarehouseID),
+			Code:        l.Code,
+			Quantity:    int64(l.Quantity),
+		})
+	}
+	pb.Results = toLineResults(r.Results)
+
+	return pb
+}
+
+// toLineResults переводит итоги строк частичного резервирования в сообщения gRPC
+func toLineResults(results []controller.LineResult) []*inventorypb.LineResult {
+	var pb []*inventorypb.LineResult
+	for _, res := range results {
+		pb = append(pb, &inventorypb.LineResult{
+			Code:        res.Code,
+			WarehouseId: int64(res.WarehouseID),
+			Requested:   int64(res.Requested),
+			Reserved:    int64(res.Reserved),
+			Available:   int64(res.Available),
+			Status:      res.Status,
+		})
+	}
+
+	return pb
+}
+
+// fromLines переводит строки запроса в строки резервирования
+func fromLines(lines []*inventorypb.ReservationLine) []controller.ReservationLine {
+	res := make([]controller.ReservationLine, 0, len(lines))
+	for _, l := range lines {
+		res = append(res, controller.ReservationLine{
+			WarehouseID: int(l.GetWarehouseId()),
+			Code:        l.GetCode(),
+			Quantity:    int(l.GetQuantity()),
+		})
+	}
+
+	return res
+}
@@ -0,0 +1,117 @@
+package rpc
+
+import (
+	"context"
+	"net"
+	"testing"
+
+	"lamoda-test/api/rpc/inventorypb"
+	"lamoda-test/pkg/client/memory"
+	"lamoda-test/utils"
+
+	"google.golang.org/genproto/googleapis/rpc/errdetails"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/credentials/insecure"
+	"google.golang.org/grpc/status"
+	"google.golang.org/grpc/test/bufconn"
+)
+
+// newClient запускает сервер на хранилище в памяти и возвращает клиента к нему
+func newClient(t *testing.T) inventorypb.InventoryServiceClient {
+	listener := bufconn.Listen(1024 * 1024)
+	server, _ := NewServer(context.Background(), memory.NewStore())
+	go server.Serve(listener)
+	t.Cleanup(server.Stop)
+
+	conn, err := grpc.Dial("bufnet",
+		grpc.WithContextDialer(func(ctx context.Context, _ string) (net.Conn, error) { return listener.DialContext(ctx) }),
+		grpc.WithTransportCredentials(insecure.NewCredentials()),
+	)
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { conn.Close() })
+
+	return inventorypb.NewInventoryServiceClient(conn)
+}
+
+func TestReserveProductsOverGRPC(t *testing.T) {
+	client := newClient(t)
+	ctx := context.Background()
+
+	w, err := client.CreateWarehouse(ctx, &inventorypb.CreateWarehouseRequest{Name: utils.RandomString(6), IsAvailable: true})
+	if err != nil {
+		t.Fatal(err)
+	}
+	p, err := client.CreateProduct(ctx, &inventorypb.CreateProductRequest{
+		Name:        utils.RandomString(6),
+		Size:        utils.RandomSize(),
+		Code:        utils.RandomString(6),
+		Quantity:    2,
+		WarehouseId: w.Id,
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	r, err := client.ReserveProducts(ctx, &inventorypb.ReserveProductsRequest{
+		OrderRef: utils.RandomString(6),
+		Lines:    []*inventorypb.ReservationLine{{Code: p.Code, Quantity: 2}},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if r.Status != "active" || len(r.Lines) != 1 || r.Lines[0].WarehouseId != w.Id {
+		t.Errorf("Unexpected reservation: %+v", r)
+	}
+
+	stock, err := client.GetWarehouseStock(ctx, &inventorypb.GetWarehouseStockRequest{WarehouseId: w.Id})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if stock.Total != 1 || stock.Items[0].ReservedQuantity != 2 || stock.Items[0].Available != 0 {
+		t.Errorf("Unexpected stock: %+v", stock)
+	}
+
+	// Ошибка предметной области приходит со статусом и кодом ошибки, как в HTTP API
+	_, err = client.ReserveProducts(ctx, &inventorypb.ReserveProductsRequest{
+		OrderRef: utils.RandomString(6),
+		Lines:    []*inventorypb.ReservationLine{{Code: p.Code, Quantity: 1}},
+	})
+	st := status.Convert(err)
+	if st.Code() != codes.FailedPrecondition {
+		t.Fatalf("Expected FailedPrecondition, got %v", err)
+	}
+	var info *errdetails.ErrorInfo
+	for _, d := range st.Details() {
+		if i, ok := d.(*errdetails.ErrorInfo); ok {
+			info = i
+		}
+	}
+	if info == nil || info.Reason != "out_of_stock" || info.Metadata["product_code"] != p.Code {
+		t.Errorf("Expected out_of_stock error info for %s, got %+v", p.Code, info)
+	}
+
+	// Если при частичном резервировании не зарезервировано ничего, итоги строк приходят в деталях ошибки
+	_, err = client.ReserveProducts(ctx, &inventorypb.ReserveProductsRequest{
+		OrderRef: utils.RandomString(6),
+		Lines:    []*inventorypb.ReservationLine{{Code: p.Code, Quantity: 1}},
+		Partial:  true,
+	})
+	st = status.Convert(err)
+	var failure *inventorypb.ReservationFailure
+	for _, d := range st.Details() {
+		if f, ok := d.(*inventorypb.ReservationFailure); ok {
+			failure = f
+		}
+	}
+	if st.Code() != codes.FailedPrecondition || failure == nil || len(failure.Results) != 1 || failure.Results[0].Status != "insufficient" {
+		t.Errorf("Expected nothing_reserved with line results, got %v %+v", err, failure)
+	}
+
+	_, err = client.GetReservation(ctx, &inventorypb.GetReservationRequest{Id: -1})
+	if status.Code(err) != codes.NotFound {
+		t.Errorf("Expected NotFound, got %v", err)
+	}
+}
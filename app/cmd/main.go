@@ -1,17 +1,27 @@
 package main
 
+// @title Warehouse API Documentation
+// @description This is a sample API for a warehouse application
+// @version 1
+// @host localhost:8080
+// @BasePath /api/v1
+
 import (
 	"context"
 	"lamoda-test/internal/app"
 	"lamoda-test/internal/config"
 	"lamoda-test/pkg/logging"
 	"log"
+	"os"
+	"os/signal"
+	"syscall"
 
 	_ "github.com/lib/pq"
 )
 
 func main() {
-	ctx, cancel := context.WithCancel(context.Background())
+	// SIGINT/SIGTERM отменяют корневой контекст и запускают плавную остановку приложения
+	ctx, cancel := signal.NotifyContext(context.Background(), syscall.SIGINT, syscall.SIGTERM)
 	defer cancel()
 	logger := logging.GetLogger(ctx)
 
@@ -21,10 +31,21 @@ func main() {
 	log.Print("logger initializing")
 	ctx = logging.ContextWithLogger(ctx, logger)
 
+	// go run . migrate up | down [N|all] | status | force VERSION
+	if len(os.Args) > 1 && os.Args[1] == "migrate" {
+		if err := app.Migrate(ctx, cfg, os.Args[2:]); err != nil {
+			logger.Fatal(err)
+		}
+		return
+	}
+
 	a, err := app.NewApp(ctx, cfg)
 	if err != nil {
 		logger.Fatal(err)
 	}
 	logger.Info("Running Application")
-	a.Run(ctx)
+	if err := a.Run(ctx); err != nil {
+		logger.Fatal(err)
+	}
+	logger.Info("application stopped")
 }
\ No newline at end of file
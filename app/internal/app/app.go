@@ -9,10 +9,14 @@ import (
 	"net/http"
 	"time"
 
+	"lamoda-test/api/controller"
 	route "lamoda-test/api/routes"
+	"lamoda-test/api/rpc"
 	config "lamoda-test/internal/config"
+	"lamoda-test/pkg/client/memory"
 	"lamoda-test/pkg/client/postgresql"
 	"lamoda-test/pkg/logging"
+	"lamoda-test/pkg/metrics"
 
 	"github.com/gin-gonic/gin"
 	_ "github.com/lib/pq"
@@ -24,31 +28,59 @@ type App struct {
 	router     *gin.Engine
 	httpServer *http.Server
 	pgClient   *sql.DB
+	store      controller.Store
+	health     *route.Health
 }
 
-func NewApp(ctx context.Context, config *config.Config) (*App, error) {
-	cfg := postgresql.NewPgConfig(config.DBUser, config.DBPass, config.DBHost, config.DBPort, config.DBName)
-	maxAttempts := 5
-	maxDelay := 3 * time.Second
-
-	pgClient, err := postgresql.NewClient(context.Background(), maxAttempts, maxDelay, cfg)
-	if err != nil {
-		return nil, err
+func NewApp(ctx context.Context, cfg *config.Config) (*App, error) {
+	var pgClient *sql.DB
+	var store controller.Store
+	switch cfg.Storage {
+	case config.StorageMemory:
+		store = memory.NewStore()
+		logging.GetLogger(ctx).Warning("in-memory storage is used, data is lost on restart")
+	case config.StoragePostgres:
+		var err error
+		pgClient, err = openPostgres(cfg)
+		if err != nil {
+			return nil, err
+		}
+		// Несовместимая схема ломает запросы посреди работы, поэтому не стартуем вовсе
+		err = prepareSchema(ctx, pgClient, cfg.MigrateOnStart)
+		if err != nil {
+			pgClient.Close()
+			return nil, err
+		}
+		err = metrics.RegisterDB(pgClient, cfg.DBName)
+		if err != nil {
+			pgClient.Close()
+			return nil, err
+		}
+		store = postgresql.NewStore(pgClient)
+	default:
+		return nil, fmt.Errorf("unknown storage %q", cfg.Storage)
 	}
 
-	router := route.NewRouter(pgClient)
+	health := route.NewHealth(pgClient, cfg.HealthCheckTimeout)
+	router := route.NewRouter(store, health, cfg.IdempotencyKeyTTL)
 	logging.GetLogger(ctx).Info("router initializing")
 
 	return &App{
-		cfg:      config,
+		cfg:      cfg,
 		router:   router,
 		pgClient: pgClient,
+		store:    store,
+		health:   health,
 	}, nil
 }
 
 func (a *App) Run(ctx context.Context) error {
 	logging.GetLogger(ctx).Info("application initialized and started")
+	// База закрывается последней, после остановки HTTP-сервера и фоновых задач
 	defer func() {
+		if a.pgClient == nil {
+			return
+		}
 		if err := a.pgClient.Close(); err != nil {
 			logging.GetLogger(ctx).Error(err)
 		}
@@ -60,6 +92,18 @@ func (a *App) Run(ctx context.Context) error {
 		return a.startHTTP(ctx)
 	})
 
+	grp.Go(func() error {
+		return a.startGRPC(ctx)
+	})
+
+	grp.Go(func() error {
+		return a.startReservationReaper(ctx)
+	})
+
+	grp.Go(func() error {
+		return a.startIdempotencyKeyPurge(ctx)
+	})
+
 	return grp.Wait()
 }
 
@@ -71,7 +115,8 @@ func (a *App) startHTTP(ctx context.Context) error {
 
 	listener, err := net.Listen("tcp", fmt.Sprintf("%s:%s", a.cfg.IP, a.cfg.Port))
 	if err != nil {
-		logging.GetLogger(ctx).WithError(err).Fatal("failed to create listener")
+		logging.GetLogger(ctx).WithError(err).Error("failed to create listener")
+		return err
 	}
 
 	handler := a.router
@@ -81,20 +126,139 @@ func (a *App) startHTTP(ctx context.Context) error {
 	}
 
 	logging.GetLogger(ctx).Info("http server completely initialized and started")
+	a.health.Started()
+
+	serveErr := make(chan error, 1)
+	go func() {
+		serveErr <- a.httpServer.Serve(listener)
+	}()
 
-	if err = a.httpServer.Serve(listener); err != nil {
-		switch {
-		case errors.Is(err, http.ErrServerClosed):
-			logging.GetLogger(ctx).Warning("server shutdown")
-		default:
-			logging.GetLogger(ctx).Fatal(err)
+	select {
+	case err = <-serveErr:
+		// Сервер остановился сам, не по сигналу: ошибка останавливает остальные задачи приложения
+		if errors.Is(err, http.ErrServerClosed) {
+			return nil
 		}
+		logging.GetLogger(ctx).Error(err)
+		return err
+	case <-ctx.Done():
+	}
+	// Проба готовности сразу начинает отвечать 503, чтобы балансировщик перестал слать новые запросы
+	a.health.Stopping()
+
+	// Новые соединения больше не принимаются, запросы в обработке дорабатывают до таймаута,
+	// чтобы не оборвать транзакции резервирования посреди выполнения
+	logging.GetLogger(ctx).WithField("timeout", a.cfg.ShutdownTimeout).Warning("server shutdown")
+	shutdownCtx, cancel := context.WithTimeout(context.Background(), a.cfg.ShutdownTimeout)
+	defer cancel()
+
+	err = a.httpServer.Shutdown(shutdownCtx)
+	if err != nil {
+		logging.GetLogger(ctx).WithError(err).Error("failed to drain http server, closing connections")
+		a.httpServer.Close()
+		return err
+	}
+
+	return nil
+}
+
+// startGRPC запускает gRPC API и при остановке приложения дожидается завершения вызовов в обработке
+func (a *App) startGRPC(ctx context.Context) error {
+	if a.cfg.GRPCPort == "" {
+		logging.GetLogger(ctx).Warning("grpc server disabled")
+		return nil
 	}
 
-	err = a.httpServer.Shutdown(context.Background())
+	listener, err := net.Listen("tcp", fmt.Sprintf("%s:%s", a.cfg.IP, a.cfg.GRPCPort))
 	if err != nil {
+		logging.GetLogger(ctx).WithError(err).Error("failed to create grpc listener")
+		return err
+	}
+
+	server, health := rpc.NewServer(ctx, a.store)
+	logging.GetLogger(ctx).WithField("PORT", a.cfg.GRPCPort).Info("grpc server started")
+
+	serveErr := make(chan error, 1)
+	go func() {
+		serveErr <- server.Serve(listener)
+	}()
+
+	select {
+	case err = <-serveErr:
 		logging.GetLogger(ctx).Error(err)
+		return err
+	case <-ctx.Done():
 	}
 
-	return err
+	// Как и HTTP, перестаем быть готовыми и даем вызовам в обработке доработать до таймаута
+	health.Shutdown()
+	stopped := make(chan struct{})
+	go func() {
+		server.GracefulStop()
+		close(stopped)
+	}()
+
+	select {
+	case <-stopped:
+	case <-time.After(a.cfg.ShutdownTimeout):
+		logging.GetLogger(ctx).Error("failed to drain grpc server, closing connections")
+		server.Stop()
+	}
+
+	return nil
+}
+
+// startReservationReaper периодически снимает просроченные резервирования
+func (a *App) startReservationReaper(ctx context.Context) error {
+	if a.cfg.ReservationSweepInterval <= 0 {
+		logging.GetLogger(ctx).Warning("reservation reaper disabled")
+		return nil
+	}
+
+	ticker := time.NewTicker(a.cfg.ReservationSweepInterval)
+	defer ticker.Stop()
+
+	logging.GetLogger(ctx).Info("reservation reaper started")
+
+	for {
+		select {
+		case <-ctx.Done():
+			return nil
+		case <-ticker.C:
+			expired, err := controller.ExpireReservations(a.store)
+			if err != nil {
+				logging.GetLogger(ctx).WithError(err).Error("failed to expire reservations")
+				continue
+			}
+			if expired > 0 {
+				logging.GetLogger(ctx).WithField("expired", expired).Info("expired reservations released")
+			}
+		}
+	}
+}
+
+// startIdempotencyKeyPurge периодически удаляет ключи идемпотентности с истекшим сроком хранения
+func (a *App) startIdempotencyKeyPurge(ctx context.Context) error {
+	if a.cfg.IdempotencyPurgeInterval <= 0 {
+		return nil
+	}
+
+	ticker := time.NewTicker(a.cfg.IdempotencyPurgeInterval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return nil
+		case <-ticker.C:
+			purged, err := controller.PurgeIdempotencyKeys(a.store, a.cfg.IdempotencyKeyTTL)
+			if err != nil {
+				logging.GetLogger(ctx).WithError(err).Error("failed to purge idempotency keys")
+				continue
+			}
+			if purged > 0 {
+				logging.GetLogger(ctx).WithField("purged", purged).Info("expired idempotency keys purged")
+			}
+		}
+	}
 }
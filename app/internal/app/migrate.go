@@ -0,0 +1,123 @@
+package app
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"strconv"
+	"time"
+
+	config "lamoda-test/internal/config"
+	"lamoda-test/migrations"
+	"lamoda-test/pkg/client/postgresql"
+	"lamoda-test/pkg/logging"
+)
+
+// openPostgres подключается к базе из конфигурации с повторными попытками
+func openPostgres(cfg *config.Config) (*sql.DB, error) {
+	pgCfg := postgresql.NewPgConfig(cfg.DBUser, cfg.DBPass, cfg.DBHost, cfg.DBPort, cfg.DBName)
+	maxAttempts := 5
+	maxDelay := 3 * time.Second
+
+	return postgresql.NewClient(context.Background(), maxAttempts, maxDelay, pgCfg)
+}
+
+// prepareSchema применяет недостающие миграции, если это разрешено, и проверяет,
+// что схема базы совпадает с версией приложения
+func prepareSchema(ctx context.Context, db *sql.DB, migrate bool) error {
+	m, err := postgresql.NewMigrator(ctx, db, migrations.FS)
+	if err != nil {
+		return err
+	}
+	defer m.Close()
+
+	if migrate {
+		from, to, err := m.Up()
+		if err != nil {
+			return err
+		}
+		if from != to {
+			logging.GetLogger(ctx).WithFields(map[string]interface{}{"from": from, "to": to}).Info("migrations applied")
+		}
+	}
+
+	return m.Check()
+}
+
+// Migrate выполняет команду управления схемой базы:
+// up, down [N|all] (по умолчанию одна миграция), status, force VERSION
+func Migrate(ctx context.Context, cfg *config.Config, args []string) error {
+	if cfg.Storage != config.StoragePostgres {
+		return fmt.Errorf("migrate requires %s storage, got %q", config.StoragePostgres, cfg.Storage)
+	}
+	if len(args) == 0 {
+		return errors.New("usage: migrate up | down [N|all] | status | force VERSION")
+	}
+
+	db, err := openPostgres(cfg)
+	if err != nil {
+		return err
+	}
+	defer db.Close()
+
+	m, err := postgresql.NewMigrator(ctx, db, migrations.FS)
+	if err != nil {
+		return err
+	}
+	defer m.Close()
+	logger := logging.GetLogger(ctx)
+
+	switch args[0] {
+	case "up":
+		from, to, err := m.Up()
+		if err != nil {
+			return err
+		}
+		if from == to {
+			logger.Info("no change")
+			break
+		}
+		logger.WithFields(map[string]interface{}{"from": from, "to": to}).Info("migrations applied")
+	case "down":
+		steps := 1
+		if len(args) > 1 {
+			if args[1] == "all" {
+				steps = -1
+			} else {
+				steps, err = strconv.Atoi(args[1])
+				if err != nil || steps <= 0 {
+					return fmt.Errorf("invalid number of migrations %q", args[1])
+				}
+			}
+		}
+		from, to, err := m.Down(steps)
+		if err != nil {
+			return err
+		}
+		logger.WithFields(map[string]interface{}{"from": from, "to": to}).Info("migrations reverted")
+	case "status":
+		version, dirty, err := m.Version()
+		if err != nil {
+			return err
+		}
+		logger.WithFields(map[string]interface{}{
+			"version": version,
+			"latest":  m.Latest(),
+			"dirty":   dirty,
+		}).Info("schema status")
+	case "force":
+		if len(args) < 2 {
+			return errors.New("usage: migrate force VERSION")
+		}
+		version, err := strconv.ParseUint(args[1], 10, 32)
+		if err != nil {
+			return fmt.Errorf("invalid version %q", args[1])
+		}
+		return m.Force(uint(version))
+	default:
+		return fmt.Errorf("unknown migrate command %q", args[0])
+	}
+
+	return nil
+}
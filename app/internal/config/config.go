@@ -1,13 +1,26 @@
 package config
 
 import (
+	"errors"
 	"log"
+	"os"
 	"sync"
+	"time"
 
 	"github.com/ilyakaznacheev/cleanenv"
 )
 
+// Хранилища данных
+const (
+	StoragePostgres = "postgres"
+	StorageMemory   = "memory"
+)
+
 type Config struct {
+	// Хранилище данных: postgres или memory.
+	// memory не требует базы, но данные теряются при перезапуске
+	Storage string `env:"STORAGE" env-default:"postgres"`
+
 	DBHost string `env:"POSTGRES_HOST" env-default:"localhost"`
 	DBPort string `env:"POSTGRES_PORT" env-default:"5432"`
 	DBUser string `env:"POSTGRES_USER"`
@@ -15,6 +28,28 @@ type Config struct {
 	DBName string `env:"POSTGRES_NAME"`
 	IP     string `env:"IP"`
 	Port   string `env:"PORT"`
+
+	// Порт gRPC API, пустое значение отключает gRPC сервер
+	GRPCPort string `env:"GRPC_PORT" env-default:"9090"`
+
+	// Применять недостающие миграции при старте. Если выключено, приложение только проверяет версию схемы
+	// и не запускается на несовместимой базе
+	MigrateOnStart bool `env:"MIGRATE_ON_START" env-default:"true"`
+
+	// Интервал снятия просроченных резервирований, 0 отключает фоновую задачу
+	ReservationSweepInterval time.Duration `env:"RESERVATION_SWEEP_INTERVAL" env-default:"1m"`
+
+	// Сколько ждать завершения запросов в обработке после SIGINT/SIGTERM, затем соединения закрываются принудительно
+	ShutdownTimeout time.Duration `env:"SHUTDOWN_TIMEOUT" env-default:"15s"`
+
+	// Таймаут проверки базы в пробе готовности /readyz
+	HealthCheckTimeout time.Duration `env:"HEALTH_CHECK_TIMEOUT" env-default:"2s"`
+
+	// Срок хранения ответов на запросы с Idempotency-Key
+	IdempotencyKeyTTL time.Duration `env:"IDEMPOTENCY_KEY_TTL" env-default:"24h"`
+
+	// Интервал удаления ключей идемпотентности старше IdempotencyKeyTTL, 0 отключает фоновую задачу
+	IdempotencyPurgeInterval time.Duration `env:"IDEMPOTENCY_PURGE_INTERVAL" env-default:"1h"`
 }
 
 var instance *Config
@@ -23,7 +58,12 @@ var once sync.Once
 func GetConfig() *Config {
 	once.Do(func() {
 		cfg := Config{}
-		if err := cleanenv.ReadConfig("../../configs/app.env", &cfg); err != nil {
+		err := cleanenv.ReadConfig("../../configs/app.env", &cfg)
+		// Без файла конфигурации берем настройки только из переменных окружения, как в CI и контейнере
+		if errors.Is(err, os.ErrNotExist) {
+			err = cleanenv.ReadEnv(&cfg)
+		}
+		if err != nil {
 			log.Fatal("failed to read config", err)
 		}
 		instance = &cfg
@@ -0,0 +1,3 @@
+DROP TABLE IF EXISTS products CASCADE;
+
+DROP TABLE IF EXISTS warehouse CASCADE;
\ No newline at end of file
@@ -0,0 +1,5 @@
+-- РЕЗЕРВЫ --
+ALTER TABLE products ADD COLUMN reserved_quantity INTEGER NOT NULL DEFAULT 0;
+
+ALTER TABLE products ADD CONSTRAINT products_reserved_quantity_check
+  CHECK (reserved_quantity >= 0 AND reserved_quantity <= quantity);
@@ -0,0 +1,3 @@
+DROP TABLE IF EXISTS reservation_lines CASCADE;
+
+DROP TABLE IF EXISTS reservations CASCADE;
@@ -0,0 +1,22 @@
+-- РЕЗЕРВИРОВАНИЯ --
+CREATE TABLE reservations (
+  id SERIAL PRIMARY KEY,
+  order_ref TEXT NOT NULL,
+  status TEXT NOT NULL DEFAULT 'active'
+    CHECK (status IN ('active', 'released', 'fulfilled', 'expired')),
+  created_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+  updated_at TIMESTAMPTZ NOT NULL DEFAULT NOW()
+);
+
+CREATE TABLE reservation_lines (
+  id SERIAL PRIMARY KEY,
+  reservation_id INTEGER NOT NULL REFERENCES reservations(id) ON DELETE CASCADE,
+  product_id INTEGER REFERENCES products(id) ON DELETE SET NULL,
+  code TEXT NOT NULL,
+  quantity INTEGER NOT NULL CHECK (quantity > 0)
+);
+
+-- СОЗДАНИЕ ИНДЕКСОВ --
+CREATE INDEX idx_reservations_order_ref ON reservations (order_ref);
+CREATE INDEX idx_reservations_status ON reservations (status);
+CREATE INDEX idx_reservation_lines_reservation_id ON reservation_lines (reservation_id);
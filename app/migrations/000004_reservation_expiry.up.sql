@@ -0,0 +1,4 @@
+-- СРОК ДЕЙСТВИЯ РЕЗЕРВИРОВАНИЙ --
+ALTER TABLE reservations ADD COLUMN expires_at TIMESTAMPTZ;
+
+CREATE INDEX idx_reservations_expires_at ON reservations (expires_at) WHERE status = 'active';
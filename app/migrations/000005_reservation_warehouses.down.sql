@@ -0,0 +1,3 @@
+ALTER TABLE reservation_lines DROP COLUMN IF EXISTS warehouse_id;
+
+ALTER TABLE reservations DROP COLUMN IF EXISTS warehouse_id;
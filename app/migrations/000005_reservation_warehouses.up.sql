@@ -0,0 +1,4 @@
+-- СКЛАДЫ В РЕЗЕРВИРОВАНИЯХ --
+ALTER TABLE reservations ADD COLUMN warehouse_id INTEGER REFERENCES warehouse(id);
+
+ALTER TABLE reservation_lines ADD COLUMN warehouse_id INTEGER REFERENCES warehouse(id);
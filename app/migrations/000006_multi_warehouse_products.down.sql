@@ -0,0 +1,7 @@
+ALTER TABLE warehouse DROP COLUMN IF EXISTS longitude;
+ALTER TABLE warehouse DROP COLUMN IF EXISTS latitude;
+ALTER TABLE warehouse DROP COLUMN IF EXISTS priority;
+
+DROP INDEX IF EXISTS idx_products_warehouse_code;
+
+ALTER TABLE products ADD CONSTRAINT products_code_key UNIQUE (code);
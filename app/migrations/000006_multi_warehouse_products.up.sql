@@ -0,0 +1,9 @@
+-- ОДИН КОД НА НЕСКОЛЬКИХ СКЛАДАХ --
+ALTER TABLE products DROP CONSTRAINT IF EXISTS products_code_key;
+
+CREATE UNIQUE INDEX idx_products_warehouse_code ON products (warehouse_id, code);
+
+-- ПАРАМЕТРЫ РАСПРЕДЕЛЕНИЯ ПО СКЛАДАМ --
+ALTER TABLE warehouse ADD COLUMN priority INTEGER NOT NULL DEFAULT 0;
+ALTER TABLE warehouse ADD COLUMN latitude DOUBLE PRECISION;
+ALTER TABLE warehouse ADD COLUMN longitude DOUBLE PRECISION;
@@ -0,0 +1,7 @@
+ALTER TABLE reservation_lines DROP CONSTRAINT IF EXISTS reservation_lines_warehouse_id_fkey;
+ALTER TABLE reservation_lines ADD CONSTRAINT reservation_lines_warehouse_id_fkey
+  FOREIGN KEY (warehouse_id) REFERENCES warehouse(id);
+
+ALTER TABLE reservations DROP CONSTRAINT IF EXISTS reservations_warehouse_id_fkey;
+ALTER TABLE reservations ADD CONSTRAINT reservations_warehouse_id_fkey
+  FOREIGN KEY (warehouse_id) REFERENCES warehouse(id);
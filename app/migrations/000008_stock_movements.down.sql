@@ -0,0 +1,3 @@
+DROP TABLE IF EXISTS stock_movements CASCADE;
+
+DROP FUNCTION IF EXISTS stock_movements_append_only();
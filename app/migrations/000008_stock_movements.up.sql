@@ -0,0 +1,29 @@
+-- ЖУРНАЛ ДВИЖЕНИЯ ОСТАТКОВ --
+-- Ссылки на продукт и склад не внешние ключи: история сохраняется после их удаления
+CREATE TABLE stock_movements (
+  id BIGSERIAL PRIMARY KEY,
+  product_id INTEGER NOT NULL,
+  warehouse_id INTEGER,
+  code TEXT NOT NULL,
+  kind TEXT NOT NULL,
+  quantity_delta INTEGER NOT NULL DEFAULT 0,
+  reserved_delta INTEGER NOT NULL DEFAULT 0,
+  reservation_id INTEGER,
+  created_at TIMESTAMPTZ NOT NULL DEFAULT NOW()
+);
+
+-- СОЗДАНИЕ ИНДЕКСОВ --
+CREATE INDEX idx_stock_movements_product_id ON stock_movements (product_id, created_at);
+CREATE INDEX idx_stock_movements_warehouse_id ON stock_movements (warehouse_id, created_at);
+CREATE INDEX idx_stock_movements_created_at ON stock_movements (created_at);
+
+-- ЖУРНАЛ ТОЛЬКО ДОПОЛНЯЕТСЯ --
+CREATE FUNCTION stock_movements_append_only() RETURNS trigger AS $$
+BEGIN
+  RAISE EXCEPTION 'stock_movements is append-only';
+END;
+$$ LANGUAGE plpgsql;
+
+CREATE TRIGGER stock_movements_append_only
+  BEFORE UPDATE OR DELETE ON stock_movements
+  FOR EACH ROW EXECUTE FUNCTION stock_movements_append_only();
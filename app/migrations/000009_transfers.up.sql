@@ -0,0 +1,14 @@
+-- ПЕРЕМЕЩЕНИЯ МЕЖДУ СКЛАДАМИ --
+CREATE TABLE transfers (
+  id SERIAL PRIMARY KEY,
+  code TEXT NOT NULL,
+  quantity INTEGER NOT NULL CHECK (quantity > 0),
+  from_warehouse_id INTEGER REFERENCES warehouse(id) ON DELETE SET NULL,
+  to_warehouse_id INTEGER REFERENCES warehouse(id) ON DELETE SET NULL,
+  created_at TIMESTAMPTZ NOT NULL DEFAULT NOW()
+);
+
+ALTER TABLE stock_movements ADD COLUMN transfer_id INTEGER;
+
+-- СОЗДАНИЕ ИНДЕКСОВ --
+CREATE INDEX idx_transfers_code ON transfers (code);
@@ -0,0 +1,4 @@
+ALTER TABLE stock_movements DROP COLUMN IF EXISTS receipt_id;
+
+DROP TABLE IF EXISTS receipt_lines CASCADE;
+DROP TABLE IF EXISTS receipts CASCADE;
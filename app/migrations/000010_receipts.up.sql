@@ -0,0 +1,21 @@
+-- ПРИЕМКА ТОВАРА --
+CREATE TABLE receipts (
+  id SERIAL PRIMARY KEY,
+  supplier_ref TEXT NOT NULL,
+  created_at TIMESTAMPTZ NOT NULL DEFAULT NOW()
+);
+
+CREATE TABLE receipt_lines (
+  id SERIAL PRIMARY KEY,
+  receipt_id INTEGER NOT NULL REFERENCES receipts(id) ON DELETE CASCADE,
+  product_id INTEGER REFERENCES products(id) ON DELETE SET NULL,
+  warehouse_id INTEGER REFERENCES warehouse(id) ON DELETE SET NULL,
+  code TEXT NOT NULL,
+  quantity INTEGER NOT NULL CHECK (quantity > 0)
+);
+
+ALTER TABLE stock_movements ADD COLUMN receipt_id INTEGER;
+
+-- СОЗДАНИЕ ИНДЕКСОВ --
+CREATE INDEX idx_receipts_supplier_ref ON receipts (supplier_ref);
+CREATE INDEX idx_receipt_lines_receipt_id ON receipt_lines (receipt_id);
@@ -0,0 +1,3 @@
+ALTER TABLE stock_movements DROP COLUMN IF EXISTS adjustment_id;
+
+DROP TABLE IF EXISTS adjustments CASCADE;
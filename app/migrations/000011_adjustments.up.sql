@@ -0,0 +1,19 @@
+-- КОРРЕКТИРОВКИ ОСТАТКОВ --
+CREATE TABLE adjustments (
+  id SERIAL PRIMARY KEY,
+  product_id INTEGER REFERENCES products(id) ON DELETE SET NULL,
+  warehouse_id INTEGER REFERENCES warehouse(id) ON DELETE SET NULL,
+  code TEXT NOT NULL,
+  reason TEXT NOT NULL
+    CHECK (reason IN ('damaged', 'lost', 'found', 'recount')),
+  quantity_before INTEGER NOT NULL,
+  quantity_after INTEGER NOT NULL CHECK (quantity_after >= 0),
+  actor TEXT NOT NULL,
+  note TEXT NOT NULL DEFAULT '',
+  created_at TIMESTAMPTZ NOT NULL DEFAULT NOW()
+);
+
+ALTER TABLE stock_movements ADD COLUMN adjustment_id INTEGER;
+
+-- СОЗДАНИЕ ИНДЕКСОВ --
+CREATE INDEX idx_adjustments_product_id ON adjustments (product_id, created_at);
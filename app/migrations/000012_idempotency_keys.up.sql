@@ -0,0 +1,16 @@
+-- КЛЮЧИ ИДЕМПОТЕНТНОСТИ --
+-- status равен NULL, пока первый запрос с ключом выполняется
+CREATE TABLE idempotency_keys (
+  key TEXT NOT NULL,
+  method TEXT NOT NULL,
+  path TEXT NOT NULL,
+  request_hash TEXT NOT NULL,
+  status INTEGER,
+  content_type TEXT NOT NULL DEFAULT '',
+  body BYTEA,
+  created_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+  PRIMARY KEY (key, method, path)
+);
+
+-- СОЗДАНИЕ ИНДЕКСОВ --
+CREATE INDEX idx_idempotency_keys_created_at ON idempotency_keys (created_at);
@@ -0,0 +1,9 @@
+// Package migrations содержит SQL-миграции схемы базы, встроенные в бинарник
+package migrations
+
+import "embed"
+
+// FS файлы миграций в формате golang-migrate: 000001_name.up.sql и 000001_name.down.sql
+//
+//go:embed *.sql
+var FS embed.FS
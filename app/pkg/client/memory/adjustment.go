@@ -0,0 +1,27 @@
+package memory
+
+import (
+	"time"
+
+	"lamoda-test/api/controller"
+)
+
+type adjustmentRepository struct {
+	repositories
+}
+
+func (r adjustmentRepository) Create(a *controller.Adjustment) error {
+	s, unlock := r.lock()
+	defer unlock()
+
+	a.ID = s.nextID("adjustments")
+	a.CreatedAt = time.Now()
+
+	// Целевой остаток и изменение не хранятся, их заменяют остатки до и после
+	stored := *a
+	stored.Quantity = nil
+	stored.Delta = nil
+	s.adjustments[a.ID] = stored
+
+	return nil
+}
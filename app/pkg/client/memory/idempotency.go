@@ -0,0 +1,93 @@
+package memory
+
+import (
+	"sync"
+	"time"
+
+	"lamoda-test/api/controller"
+)
+
+// idempotencyKey ключ идемпотентности действует в пределах метода и пути
+type idempotencyKey struct {
+	key    string
+	method string
+	path   string
+}
+
+// idempotencyRepository ключи идемпотентности живут вне транзакций и защищены своей блокировкой
+type idempotencyRepository struct {
+	mu   sync.Mutex
+	keys map[idempotencyKey]controller.IdempotentRequest
+}
+
+func newIdempotencyRepository() *idempotencyRepository {
+	return &idempotencyRepository{keys: map[idempotencyKey]controller.IdempotentRequest{}}
+}
+
+func keyOf(req *controller.IdempotentRequest) idempotencyKey {
+	return idempotencyKey{key: req.Key, method: req.Method, path: req.Path}
+}
+
+func (r *idempotencyRepository) Claim(req *controller.IdempotentRequest, ttl, lockTimeout time.Duration) (*controller.IdempotentRequest, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	k := keyOf(req)
+	now := time.Now()
+	if stored, ok := r.keys[k]; ok {
+		// Освобождаем ключ с истекшим сроком хранения или брошенный незавершенным запросом
+		age := now.Sub(stored.CreatedAt)
+		if age <= ttl && (stored.Status != 0 || age <= lockTimeout) {
+			stored.Body = append([]byte(nil), stored.Body...)
+			return &stored, nil
+		}
+	}
+
+	r.keys[k] = controller.IdempotentRequest{Key: req.Key, Method: req.Method, Path: req.Path, RequestHash: req.RequestHash, CreatedAt: now}
+
+	return nil, nil
+}
+
+func (r *idempotencyRepository) Save(req *controller.IdempotentRequest) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	k := keyOf(req)
+	stored, ok := r.keys[k]
+	if !ok {
+		return nil
+	}
+	stored.Status = req.Status
+	stored.ContentType = req.ContentType
+	stored.Body = append([]byte(nil), req.Body...)
+	r.keys[k] = stored
+
+	return nil
+}
+
+func (r *idempotencyRepository) Release(req *controller.IdempotentRequest) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	k := keyOf(req)
+	if stored, ok := r.keys[k]; ok && stored.Status == 0 {
+		delete(r.keys, k)
+	}
+
+	return nil
+}
+
+func (r *idempotencyRepository) Purge(ttl time.Duration) (int, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	purged := 0
+	for k, stored := range r.keys {
+		if time.Since(stored.CreatedAt) > ttl {
+			delete(r.keys, k)
+			purged++
+		}
+	}
+
+	return purged, nil
+}
@@ -0,0 +1,58 @@
+package memory
+
+import (
+	"sort"
+	"time"
+
+	"lamoda-test/api/controller"
+)
+
+type movementRepository struct {
+	repositories
+}
+
+func (r movementRepository) Record(m controller.StockMovement) error {
+	s, unlock := r.lock()
+	defer unlock()
+
+	m.ID = int64(s.nextID("stock_movements"))
+	m.CreatedAt = time.Now()
+	m.ReservationID = copyID(m.ReservationID)
+	m.TransferID = copyID(m.TransferID)
+	m.ReceiptID = copyID(m.ReceiptID)
+	m.AdjustmentID = copyID(m.AdjustmentID)
+	s.movements = append(s.movements, m)
+
+	return nil
+}
+
+func (r movementRepository) List(f controller.MovementFilter) (*controller.MovementPage, error) {
+	s, unlock := r.lock()
+	defer unlock()
+
+	movements := []controller.StockMovement{}
+	for _, m := range s.movements {
+		switch {
+		case f.ProductID != 0 && m.ProductID != f.ProductID,
+			f.WarehouseID != 0 && m.WarehouseID != f.WarehouseID,
+			f.Code != "" && m.Code != f.Code,
+			f.Kind != "" && m.Kind != f.Kind,
+			!f.From.IsZero() && m.CreatedAt.Before(f.From),
+			!f.To.IsZero() && !m.CreatedAt.Before(f.To):
+			continue
+		}
+		movements = append(movements, m)
+	}
+
+	// Новые записи первыми, как в базе
+	sort.Slice(movements, func(i, j int) bool {
+		if !movements[i].CreatedAt.Equal(movements[j].CreatedAt) {
+			return movements[i].CreatedAt.After(movements[j].CreatedAt)
+		}
+		return movements[i].ID > movements[j].ID
+	})
+
+	page := controller.MovementPage{Items: paginate(movements, f.Limit, f.Offset), Total: len(movements), Limit: f.Limit, Offset: f.Offset}
+
+	return &page, nil
+}
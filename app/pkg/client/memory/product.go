@@ -0,0 +1,337 @@
+package memory
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+
+	"lamoda-test/api/controller"
+)
+
+// productSortKeys значения полей сортировки списка продуктов
+var productSortKeys = map[string]func(p controller.Product) interface{}{
+	"id":        func(p controller.Product) interface{} { return p.ID },
+	"name":      func(p controller.Product) interface{} { return p.Name },
+	"code":      func(p controller.Product) interface{} { return p.Code },
+	"size":      func(p controller.Product) interface{} { return p.Size },
+	"quantity":  func(p controller.Product) interface{} { return p.Quantity },
+	"reserved":  func(p controller.Product) interface{} { return p.ReservedQuantity },
+	"available": func(p controller.Product) interface{} { return p.Available },
+}
+
+type productRepository struct {
+	repositories
+}
+
+// checkProduct проверяет ограничения таблицы продуктов
+func checkProduct(p controller.Product) error {
+	if p.ReservedQuantity < 0 || p.ReservedQuantity > p.Quantity {
+		return fmt.Errorf("product %d violates products_reserved_quantity_check", p.ID)
+	}
+
+	return nil
+}
+
+// findProduct возвращает продукт с кодом на складе
+func (s *state) findProduct(warehouseID int, code string) (controller.Product, bool) {
+	for _, p := range s.products {
+		if p.WarehouseID == warehouseID && p.Code == code {
+			return p, true
+		}
+	}
+
+	return controller.Product{}, false
+}
+
+// insertProduct сохраняет новый продукт, проверяя склад и уникальность кода на складе
+func (s *state) insertProduct(p *controller.Product) error {
+	if _, ok := s.warehouses[p.WarehouseID]; !ok {
+		return controller.ErrWarehouseNotFound.WithWarehouse(p.WarehouseID)
+	}
+	if _, ok := s.findProduct(p.WarehouseID, p.Code); ok {
+		return controller.ErrProductExists.WithProduct(p.Code)
+	}
+
+	stored := *p
+	stored.ID = s.nextID("products")
+	stored.ReservedQuantity = 0
+	if err := checkProduct(stored); err != nil {
+		return err
+	}
+	s.products[stored.ID] = stored
+	p.ID = stored.ID
+
+	return nil
+}
+
+// selectProducts возвращает продукты, подходящие под match, в порядке less
+func (s *state) selectProducts(match func(p controller.Product) bool, less func(a, b controller.Product) bool) []controller.Product {
+	products := []controller.Product{}
+	for _, p := range s.products {
+		if match(p) {
+			p.Available = p.Quantity - p.ReservedQuantity
+			products = append(products, p)
+		}
+	}
+	sort.Slice(products, func(i, j int) bool { return less(products[i], products[j]) })
+
+	return products
+}
+
+// byID упорядочивает продукты по ID, как блокировки в базе
+func byID(a, b controller.Product) bool {
+	return a.ID < b.ID
+}
+
+// byCode упорядочивает продукты по коду, затем по ID, как блокировки продуктов склада в базе
+func byCode(a, b controller.Product) bool {
+	if a.Code != b.Code {
+		return a.Code < b.Code
+	}
+	return a.ID < b.ID
+}
+
+func (r productRepository) Create(p *controller.Product) error {
+	s, unlock := r.lock()
+	defer unlock()
+
+	return s.insertProduct(p)
+}
+
+func (r productRepository) Restock(p *controller.Product) error {
+	s, unlock := r.lock()
+	defer unlock()
+
+	existing, ok := s.findProduct(p.WarehouseID, p.Code)
+	if !ok {
+		return s.insertProduct(p)
+	}
+	existing.Quantity += p.Quantity
+	if err := checkProduct(existing); err != nil {
+		return err
+	}
+	s.products[existing.ID] = existing
+	p.ID = existing.ID
+
+	return nil
+}
+
+func (r productRepository) CreateIfMissing(p *controller.Product) error {
+	s, unlock := r.lock()
+	defer unlock()
+
+	if _, ok := s.findProduct(p.WarehouseID, p.Code); ok {
+		return nil
+	}
+
+	return s.insertProduct(p)
+}
+
+func (r productRepository) Get(id int) (*controller.Product, error) {
+	s, unlock := r.lock()
+	defer unlock()
+
+	p, ok := s.products[id]
+	if !ok {
+		return nil, controller.ErrProductNotFound
+	}
+	p.Available = p.Quantity - p.ReservedQuantity
+
+	return &p, nil
+}
+
+func (r productRepository) GetForUpdate(id int) (*controller.Product, error) {
+	return r.Get(id)
+}
+
+func (r productRepository) List(f controller.ProductFilter) (*controller.ProductPage, error) {
+	less := byID
+	if f.Sort != "" {
+		field, desc := f.Sort, false
+		if strings.HasPrefix(field, "-") {
+			field, desc = field[1:], true
+		}
+		key, ok := productSortKeys[field]
+		if !ok {
+			return nil, controller.ErrInvalidSort
+		}
+		less = func(a, b controller.Product) bool {
+			ka, kb := key(a), key(b)
+			if ka != kb {
+				return lessValue(ka, kb) != desc
+			}
+			return a.ID < b.ID
+		}
+	}
+
+	name := strings.ToLower(f.Name)
+	match := func(p controller.Product) bool {
+		available := p.Quantity - p.ReservedQuantity
+		switch {
+		case f.WarehouseID != 0 && p.WarehouseID != f.WarehouseID,
+			name != "" && !strings.Contains(strings.ToLower(p.Name), name),
+			f.Size != "" && p.Size != f.Size,
+			f.MinQuantity != nil && p.Quantity < *f.MinQuantity,
+			f.MaxQuantity != nil && p.Quantity > *f.MaxQuantity,
+			f.OutOfStock && available > 0:
+			return false
+		}
+		return true
+	}
+
+	s, unlock := r.lock()
+	defer unlock()
+
+	products := s.selectProducts(match, less)
+	page := controller.ProductPage{Items: paginate(products, f.Limit, f.Offset), Total: len(products), Limit: f.Limit, Offset: f.Offset}
+
+	return &page, nil
+}
+
+func (r productRepository) ListByCode(code string) ([]controller.Product, error) {
+	s, unlock := r.lock()
+	defer unlock()
+
+	return s.selectProducts(func(p controller.Product) bool { return p.Code == code }, func(a, b controller.Product) bool {
+		return a.WarehouseID < b.WarehouseID
+	}), nil
+}
+
+func (r productRepository) LockByCode(code string, warehouseIDs ...int) ([]controller.Product, error) {
+	s, unlock := r.lock()
+	defer unlock()
+
+	return s.selectProducts(func(p controller.Product) bool {
+		if p.Code != code {
+			return false
+		}
+		if len(warehouseIDs) == 0 {
+			return true
+		}
+		for _, id := range warehouseIDs {
+			if p.WarehouseID == id {
+				return true
+			}
+		}
+		return false
+	}, byID), nil
+}
+
+func (r productRepository) LockByWarehouse(warehouseID int) ([]controller.Product, error) {
+	s, unlock := r.lock()
+	defer unlock()
+
+	return s.selectProducts(func(p controller.Product) bool { return p.WarehouseID == warehouseID }, byCode), nil
+}
+
+func (r productRepository) LockStock(code string, warehouseID int) ([]controller.StockLevel, error) {
+	s, unlock := r.lock()
+	defer unlock()
+
+	products := s.selectProducts(func(p controller.Product) bool {
+		return p.Code == code && (warehouseID == 0 || p.WarehouseID == warehouseID)
+	}, byID)
+
+	var stock []controller.StockLevel
+	for _, p := range products {
+		w, ok := s.warehouses[p.WarehouseID]
+		if !ok {
+			continue
+		}
+		stock = append(stock, controller.StockLevel{ProductID: p.ID, Warehouse: w, Available: p.Available})
+	}
+
+	return stock, nil
+}
+
+func (r productRepository) Update(p *controller.Product) error {
+	s, unlock := r.lock()
+	defer unlock()
+
+	stored, ok := s.products[p.ID]
+	if !ok {
+		return nil
+	}
+	stored.Name = p.Name
+	stored.Size = p.Size
+	stored.Quantity = p.Quantity
+	if err := checkProduct(stored); err != nil {
+		return err
+	}
+	s.products[p.ID] = stored
+
+	return nil
+}
+
+func (r productRepository) AddStock(id, quantityDelta, reservedDelta int) error {
+	s, unlock := r.lock()
+	defer unlock()
+
+	stored, ok := s.products[id]
+	if !ok {
+		return nil
+	}
+	stored.Quantity += quantityDelta
+	stored.ReservedQuantity += reservedDelta
+	if err := checkProduct(stored); err != nil {
+		return err
+	}
+	s.products[id] = stored
+
+	return nil
+}
+
+func (r productRepository) Delete(id int) error {
+	s, unlock := r.lock()
+	defer unlock()
+
+	// Ссылки на продукт обнуляются, как ON DELETE SET NULL
+	for _, res := range s.reservations {
+		for i := range res.Lines {
+			if res.Lines[i].ProductID == id {
+				res.Lines[i].ProductID = 0
+			}
+		}
+	}
+	for _, rc := range s.receipts {
+		for i := range rc.Lines {
+			if rc.Lines[i].ProductID == id {
+				rc.Lines[i].ProductID = 0
+			}
+		}
+	}
+	for aid, a := range s.adjustments {
+		if a.ProductID == id {
+			a.ProductID = 0
+			s.adjustments[aid] = a
+		}
+	}
+	delete(s.products, id)
+
+	return nil
+}
+
+// lessValue сравнивает значения одного поля сортировки
+func lessValue(a, b interface{}) bool {
+	switch a := a.(type) {
+	case int:
+		return a < b.(int)
+	case string:
+		return a < b.(string)
+	}
+
+	return false
+}
+
+// paginate возвращает страницу среза
+func paginate[T any](items []T, limit, offset int) []T {
+	if offset >= len(items) {
+		return []T{}
+	}
+	end := offset + limit
+	if end > len(items) {
+		end = len(items)
+	}
+
+	return items[offset:end]
+}
@@ -0,0 +1,41 @@
+package memory
+
+import (
+	"time"
+
+	"lamoda-test/api/controller"
+)
+
+type receiptRepository struct {
+	repositories
+}
+
+func (r receiptRepository) Create(rc *controller.Receipt) error {
+	s, unlock := r.lock()
+	defer unlock()
+
+	rc.ID = s.nextID("receipts")
+	rc.CreatedAt = time.Now()
+
+	// Название и размер строки нужны только для создания продукта и не хранятся
+	lines := make([]controller.ReceiptLine, 0, len(rc.Lines))
+	for _, l := range rc.Lines {
+		lines = append(lines, controller.ReceiptLine{ProductID: l.ProductID, WarehouseID: l.WarehouseID, Code: l.Code, Quantity: l.Quantity})
+	}
+	s.receipts[rc.ID] = controller.Receipt{ID: rc.ID, SupplierRef: rc.SupplierRef, Lines: lines, CreatedAt: rc.CreatedAt}
+
+	return nil
+}
+
+func (r receiptRepository) Get(id int) (*controller.Receipt, error) {
+	s, unlock := r.lock()
+	defer unlock()
+
+	rc, ok := s.receipts[id]
+	if !ok {
+		return nil, controller.ErrReceiptNotFound
+	}
+	rc.Lines = append([]controller.ReceiptLine{}, rc.Lines...)
+
+	return &rc, nil
+}
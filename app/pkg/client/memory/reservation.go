@@ -0,0 +1,151 @@
+package memory
+
+import (
+	"sort"
+	"time"
+
+	"lamoda-test/api/controller"
+)
+
+type reservationRepository struct {
+	repositories
+}
+
+func (r reservationRepository) Create(res *controller.Reservation) error {
+	s, unlock := r.lock()
+	defer unlock()
+
+	now := time.Now()
+	res.ID = s.nextID("reservations")
+	res.CreatedAt = now
+	res.UpdatedAt = now
+
+	// Сохраняем только то, что хранится в таблицах резервирований
+	s.reservations[res.ID] = controller.Reservation{
+		ID:          res.ID,
+		OrderRef:    res.OrderRef,
+		WarehouseID: res.WarehouseID,
+		Status:      res.Status,
+		Lines:       append([]controller.ReservationLine{}, res.Lines...),
+		CreatedAt:   res.CreatedAt,
+		UpdatedAt:   res.UpdatedAt,
+		ExpiresAt:   res.ExpiresAt,
+	}
+
+	return nil
+}
+
+func (r reservationRepository) Get(id int) (*controller.Reservation, error) {
+	s, unlock := r.lock()
+	defer unlock()
+
+	res, ok := s.reservations[id]
+	if !ok {
+		return nil, controller.ErrReservationNotFound
+	}
+	res.Lines = append([]controller.ReservationLine{}, res.Lines...)
+
+	return &res, nil
+}
+
+func (r reservationRepository) GetForUpdate(id int) (*controller.Reservation, error) {
+	return r.Get(id)
+}
+
+func (r reservationRepository) SetStatus(res *controller.Reservation, status string) error {
+	s, unlock := r.lock()
+	defer unlock()
+
+	stored, ok := s.reservations[res.ID]
+	if !ok {
+		return controller.ErrReservationNotFound
+	}
+	stored.Status = status
+	stored.UpdatedAt = time.Now()
+	s.reservations[res.ID] = stored
+	res.Status = stored.Status
+	res.UpdatedAt = stored.UpdatedAt
+
+	return nil
+}
+
+func (r reservationRepository) ListExpired(now time.Time) ([]int, error) {
+	s, unlock := r.lock()
+	defer unlock()
+
+	var expired []controller.Reservation
+	for _, res := range s.reservations {
+		if res.Status == controller.ReservationActive && res.ExpiresAt != nil && !res.ExpiresAt.After(now) {
+			expired = append(expired, res)
+		}
+	}
+	sort.Slice(expired, func(i, j int) bool {
+		if !expired[i].ExpiresAt.Equal(*expired[j].ExpiresAt) {
+			return expired[i].ExpiresAt.Before(*expired[j].ExpiresAt)
+		}
+		return expired[i].ID < expired[j].ID
+	})
+
+	ids := make([]int, 0, len(expired))
+	for _, res := range expired {
+		ids = append(ids, res.ID)
+	}
+
+	return ids, nil
+}
+
+func (r reservationRepository) ListHolding(productID int) ([]controller.Reservation, error) {
+	s, unlock := r.lock()
+	defer unlock()
+
+	var holding []controller.Reservation
+	for _, res := range s.reservations {
+		if res.Status != controller.ReservationActive {
+			continue
+		}
+		for _, l := range res.Lines {
+			if l.ProductID == productID {
+				res.Lines = append([]controller.ReservationLine{}, res.Lines...)
+				holding = append(holding, res)
+				break
+			}
+		}
+	}
+	sort.Slice(holding, func(i, j int) bool {
+		if !holding[i].CreatedAt.Equal(holding[j].CreatedAt) {
+			return holding[i].CreatedAt.After(holding[j].CreatedAt)
+		}
+		return holding[i].ID > holding[j].ID
+	})
+
+	return holding, nil
+}
+
+func (r reservationRepository) ReduceLines(reservationID, productID, quantity int) error {
+	s, unlock := r.lock()
+	defer unlock()
+
+	stored, ok := s.reservations[reservationID]
+	if !ok {
+		return controller.ErrReservationNotFound
+	}
+	lines := make([]controller.ReservationLine, 0, len(stored.Lines))
+	for _, l := range stored.Lines {
+		if l.ProductID == productID && quantity > 0 {
+			take := l.Quantity
+			if take > quantity {
+				take = quantity
+			}
+			l.Quantity -= take
+			quantity -= take
+		}
+		// Строки без единиц удаляются, как в таблице с проверкой quantity > 0
+		if l.Quantity > 0 {
+			lines = append(lines, l)
+		}
+	}
+	stored.Lines = lines
+	s.reservations[reservationID] = stored
+
+	return nil
+}
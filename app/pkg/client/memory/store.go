@@ -0,0 +1,173 @@
+package memory
+
+import (
+	"sync"
+
+	"lamoda-test/api/controller"
+)
+
+var _ controller.Store = (*Store)(nil)
+
+// Store хранилище данных склада в памяти процесса.
+// Транзакции выполняются по очереди под общей блокировкой, при ошибке состояние восстанавливается из снимка.
+// Ограничения и внешние ключи таблиц воспроизводятся настолько, насколько на них полагаются контроллеры
+type Store struct {
+	repositories
+	mu          sync.Mutex
+	state       *state
+	idempotency *idempotencyRepository
+}
+
+// NewStore создает пустое хранилище
+func NewStore() *Store {
+	s := &Store{state: newState(), idempotency: newIdempotencyRepository()}
+	s.repositories = repositories{store: s}
+
+	return s
+}
+
+// InTx выполняет fn под блокировкой хранилища и откатывает изменения, если fn вернула ошибку.
+// Внутри fn нужно обращаться только к хранилищам tx, обращение к Store заблокируется
+func (s *Store) InTx(fn func(tx controller.Repositories) error) error {
+	s.mu.Lock()
+	snapshot := s.state.clone()
+	committed := false
+	// Состояние восстанавливается и при панике в fn, чтобы хранилище не осталось наполовину измененным
+	defer func() {
+		if !committed {
+			s.state = snapshot
+		}
+		s.mu.Unlock()
+	}()
+
+	err := fn(repositories{store: s, inTx: true})
+	if err != nil {
+		return err
+	}
+	committed = true
+
+	return nil
+}
+
+// Idempotency возвращает хранилище ключей идемпотентности
+func (s *Store) Idempotency() controller.IdempotencyRepository {
+	return s.idempotency
+}
+
+// repositories хранилища поверх общего состояния.
+// Вне транзакции каждый вызов берет блокировку хранилища сам
+type repositories struct {
+	store *Store
+	inTx  bool
+}
+
+// lock блокирует хранилище на время вызова вне транзакции и возвращает состояние и функцию снятия блокировки
+func (r repositories) lock() (*state, func()) {
+	if r.inTx {
+		return r.store.state, func() {}
+	}
+	r.store.mu.Lock()
+
+	return r.store.state, r.store.mu.Unlock
+}
+
+func (r repositories) Warehouses() controller.WarehouseRepository {
+	return warehouseRepository{r}
+}
+
+func (r repositories) Products() controller.ProductRepository {
+	return productRepository{r}
+}
+
+func (r repositories) Reservations() controller.ReservationRepository {
+	return reservationRepository{r}
+}
+
+func (r repositories) Movements() controller.MovementRepository {
+	return movementRepository{r}
+}
+
+func (r repositories) Transfers() controller.TransferRepository {
+	return transferRepository{r}
+}
+
+func (r repositories) Receipts() controller.ReceiptRepository {
+	return receiptRepository{r}
+}
+
+func (r repositories) Adjustments() controller.AdjustmentRepository {
+	return adjustmentRepository{r}
+}
+
+// state таблицы хранилища, строки хранятся по значению
+type state struct {
+	warehouses   map[int]controller.Warehouse
+	products     map[int]controller.Product
+	reservations map[int]controller.Reservation
+	movements    []controller.StockMovement
+	transfers    map[int]controller.Transfer
+	receipts     map[int]controller.Receipt
+	adjustments  map[int]controller.Adjustment
+	sequences    map[string]int
+}
+
+func newState() *state {
+	return &state{
+		warehouses:   map[int]controller.Warehouse{},
+		products:     map[int]controller.Product{},
+		reservations: map[int]controller.Reservation{},
+		transfers:    map[int]controller.Transfer{},
+		receipts:     map[int]controller.Receipt{},
+		adjustments:  map[int]controller.Adjustment{},
+		sequences:    map[string]int{},
+	}
+}
+
+// nextID возвращает следующий ID таблицы, как последовательность SERIAL
+func (s *state) nextID(table string) int {
+	s.sequences[table]++
+
+	return s.sequences[table]
+}
+
+// clone копирует состояние для отката транзакции.
+// Строки резервирований и приемок копируются, потому что внешние ключи меняют их на месте
+func (s *state) clone() *state {
+	c := newState()
+	for id, w := range s.warehouses {
+		c.warehouses[id] = w
+	}
+	for id, p := range s.products {
+		c.products[id] = p
+	}
+	for id, r := range s.reservations {
+		r.Lines = append([]controller.ReservationLine{}, r.Lines...)
+		c.reservations[id] = r
+	}
+	c.movements = append(c.movements, s.movements...)
+	for id, t := range s.transfers {
+		c.transfers[id] = t
+	}
+	for id, r := range s.receipts {
+		r.Lines = append([]controller.ReceiptLine{}, r.Lines...)
+		c.receipts[id] = r
+	}
+	for id, a := range s.adjustments {
+		c.adjustments[id] = a
+	}
+	for table, id := range s.sequences {
+		c.sequences[table] = id
+	}
+
+	return c
+}
+
+// copyID копирует необязательную ссылку, чтобы сохраненная строка не зависела от переменной вызывающего
+func copyID(id *int) *int {
+	if id == nil {
+		return nil
+	}
+	v := *id
+
+	return &v
+}
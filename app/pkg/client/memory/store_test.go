@@ -0,0 +1,161 @@
+package memory
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	"lamoda-test/api/controller"
+)
+
+// seed создает склад с продуктом вне транзакции
+func seed(t *testing.T, s *Store) (*controller.Warehouse, *controller.Product) {
+	t.Helper()
+
+	w := &controller.Warehouse{Name: "north", IsAvailable: true}
+	if err := s.Warehouses().Create(w); err != nil {
+		t.Fatal(err)
+	}
+	p := &controller.Product{Name: "shirt", Code: "SHIRT", Quantity: 5, WarehouseID: w.ID}
+	if err := s.Products().Create(p); err != nil {
+		t.Fatal(err)
+	}
+
+	return w, p
+}
+
+// change изменяет существующие склад и продукт и добавляет новый склад
+func change(tx controller.Repositories, w *controller.Warehouse, p *controller.Product) error {
+	changed := *w
+	changed.Name = "south"
+	if err := tx.Warehouses().Update(&changed); err != nil {
+		return err
+	}
+	if err := tx.Products().AddStock(p.ID, -3, 1); err != nil {
+		return err
+	}
+
+	return tx.Warehouses().Create(&controller.Warehouse{Name: "east"})
+}
+
+// checkUnchanged проверяет, что состояние совпадает с созданным seed
+func checkUnchanged(t *testing.T, s *Store, w *controller.Warehouse, p *controller.Product) {
+	t.Helper()
+
+	warehouses, err := s.Warehouses().List()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(warehouses) != 1 || warehouses[0].Name != w.Name {
+		t.Errorf("Expected only warehouse %q, got %+v", w.Name, warehouses)
+	}
+	got, err := s.Products().Get(p.ID)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got.Quantity != p.Quantity || got.ReservedQuantity != 0 {
+		t.Errorf("Expected product stock to be restored, got %+v", got)
+	}
+}
+
+func TestInTxRollsBackOnError(t *testing.T) {
+	s := NewStore()
+	w, p := seed(t, s)
+
+	errFailed := errors.New("failed")
+	err := s.InTx(func(tx controller.Repositories) error {
+		if err := change(tx, w, p); err != nil {
+			return err
+		}
+		return errFailed
+	})
+	if !errors.Is(err, errFailed) {
+		t.Fatalf("Expected error from fn, got %v", err)
+	}
+
+	checkUnchanged(t, s, w, p)
+}
+
+func TestInTxRestoresOnPanic(t *testing.T) {
+	s := NewStore()
+	w, p := seed(t, s)
+
+	func() {
+		defer func() {
+			if recover() == nil {
+				t.Error("Expected panic to propagate from InTx")
+			}
+		}()
+		s.InTx(func(tx controller.Repositories) error {
+			if err := change(tx, w, p); err != nil {
+				return err
+			}
+			panic("handler failed")
+		})
+	}()
+
+	// Блокировка снята, хранилище снова доступно
+	checkUnchanged(t, s, w, p)
+}
+
+func TestInTxCommits(t *testing.T) {
+	s := NewStore()
+	w, p := seed(t, s)
+
+	err := s.InTx(func(tx controller.Repositories) error {
+		return change(tx, w, p)
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	warehouses, err := s.Warehouses().List()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(warehouses) != 2 || warehouses[0].Name != "south" {
+		t.Errorf("Expected changed and created warehouses, got %+v", warehouses)
+	}
+	got, err := s.Products().Get(p.ID)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got.Quantity != 2 || got.ReservedQuantity != 1 {
+		t.Errorf("Expected quantity 2 and reserved 1, got %+v", got)
+	}
+}
+
+func TestInTxHoldsLock(t *testing.T) {
+	s := NewStore()
+
+	entered := make(chan struct{})
+	release := make(chan struct{})
+	txDone := make(chan struct{})
+	go func() {
+		defer close(txDone)
+		s.InTx(func(tx controller.Repositories) error {
+			close(entered)
+			<-release
+			return tx.Warehouses().Create(&controller.Warehouse{Name: "north"})
+		})
+	}()
+	<-entered
+
+	// Обращение к хранилищу вне транзакции ждет ее завершения и видит ее изменения
+	listed := make(chan []controller.Warehouse)
+	go func() {
+		warehouses, _ := s.Warehouses().List()
+		listed <- warehouses
+	}()
+	select {
+	case <-listed:
+		t.Fatal("Expected List to wait for the transaction")
+	case <-time.After(50 * time.Millisecond):
+	}
+
+	close(release)
+	<-txDone
+	if warehouses := <-listed; len(warehouses) != 1 {
+		t.Errorf("Expected List to see the committed warehouse, got %+v", warehouses)
+	}
+}
@@ -0,0 +1,22 @@
+package memory
+
+import (
+	"time"
+
+	"lamoda-test/api/controller"
+)
+
+type transferRepository struct {
+	repositories
+}
+
+func (r transferRepository) Create(t *controller.Transfer) error {
+	s, unlock := r.lock()
+	defer unlock()
+
+	t.ID = s.nextID("transfers")
+	t.CreatedAt = time.Now()
+	s.transfers[t.ID] = *t
+
+	return nil
+}
@@ -0,0 +1,117 @@
+package memory
+
+import (
+	"fmt"
+	"sort"
+
+	"lamoda-test/api/controller"
+)
+
+type warehouseRepository struct {
+	repositories
+}
+
+func (r warehouseRepository) Create(w *controller.Warehouse) error {
+	s, unlock := r.lock()
+	defer unlock()
+
+	w.ID = s.nextID("warehouse")
+	s.warehouses[w.ID] = *w
+
+	return nil
+}
+
+func (r warehouseRepository) List() ([]controller.Warehouse, error) {
+	s, unlock := r.lock()
+	defer unlock()
+
+	warehouses := []controller.Warehouse{}
+	for _, w := range s.warehouses {
+		warehouses = append(warehouses, w)
+	}
+	sort.Slice(warehouses, func(i, j int) bool { return warehouses[i].ID < warehouses[j].ID })
+
+	return warehouses, nil
+}
+
+func (r warehouseRepository) Get(id int) (*controller.Warehouse, error) {
+	s, unlock := r.lock()
+	defer unlock()
+
+	w, ok := s.warehouses[id]
+	if !ok {
+		return nil, controller.ErrWarehouseNotFound.WithWarehouse(id)
+	}
+
+	return &w, nil
+}
+
+// GetForShare в памяти не отличается от Get: транзакции и так выполняются по очереди
+func (r warehouseRepository) GetForShare(id int) (*controller.Warehouse, error) {
+	return r.Get(id)
+}
+
+func (r warehouseRepository) GetForUpdate(id int) (*controller.Warehouse, error) {
+	return r.Get(id)
+}
+
+func (r warehouseRepository) Update(w *controller.Warehouse) error {
+	s, unlock := r.lock()
+	defer unlock()
+
+	if _, ok := s.warehouses[w.ID]; ok {
+		s.warehouses[w.ID] = *w
+	}
+
+	return nil
+}
+
+func (r warehouseRepository) Delete(id int) error {
+	s, unlock := r.lock()
+	defer unlock()
+
+	// Продукты ссылаются на склад без ON DELETE, как и в базе
+	for _, p := range s.products {
+		if p.WarehouseID == id {
+			return fmt.Errorf("warehouse %d is still referenced by product %d", id, p.ID)
+		}
+	}
+
+	// Остальные ссылки обнуляются, как ON DELETE SET NULL
+	for rid, res := range s.reservations {
+		if res.WarehouseID == id {
+			res.WarehouseID = 0
+		}
+		for i := range res.Lines {
+			if res.Lines[i].WarehouseID == id {
+				res.Lines[i].WarehouseID = 0
+			}
+		}
+		s.reservations[rid] = res
+	}
+	for tid, t := range s.transfers {
+		if t.FromWarehouseID == id {
+			t.FromWarehouseID = 0
+		}
+		if t.ToWarehouseID == id {
+			t.ToWarehouseID = 0
+		}
+		s.transfers[tid] = t
+	}
+	for _, rc := range s.receipts {
+		for i := range rc.Lines {
+			if rc.Lines[i].WarehouseID == id {
+				rc.Lines[i].WarehouseID = 0
+			}
+		}
+	}
+	for aid, a := range s.adjustments {
+		if a.WarehouseID == id {
+			a.WarehouseID = 0
+			s.adjustments[aid] = a
+		}
+	}
+	delete(s.warehouses, id)
+
+	return nil
+}
@@ -0,0 +1,13 @@
+package postgresql
+
+import "lamoda-test/api/controller"
+
+type adjustmentRepository struct {
+	q querier
+}
+
+func (r adjustmentRepository) Create(a *controller.Adjustment) error {
+	return r.q.QueryRow(`INSERT INTO adjustments(product_id, warehouse_id, code, reason, quantity_before, quantity_after, actor, note)
+		VALUES($1, $2, $3, $4, $5, $6, $7, $8) RETURNING id, created_at`,
+		a.ProductID, a.WarehouseID, a.Code, a.Reason, a.QuantityBefore, a.QuantityAfter, a.Actor, a.Note).Scan(&a.ID, &a.CreatedAt)
+}
@@ -0,0 +1,78 @@
+package postgresql
+
+import (
+	"database/sql"
+	"errors"
+	"time"
+
+	"lamoda-test/api/controller"
+)
+
+type idempotencyRepository struct {
+	db *sql.DB
+}
+
+func (r idempotencyRepository) Claim(req *controller.IdempotentRequest, ttl, lockTimeout time.Duration) (*controller.IdempotentRequest, error) {
+	// Освобождаем ключ с истекшим сроком хранения или брошенный незавершенным запросом
+	_, err := r.db.Exec(`DELETE FROM idempotency_keys WHERE key = $1 AND method = $2 AND path = $3
+		AND (created_at < NOW() - make_interval(secs => $4) OR (status IS NULL AND created_at < NOW() - make_interval(secs => $5)))`,
+		req.Key, req.Method, req.Path, ttl.Seconds(), lockTimeout.Seconds())
+	if err != nil {
+		return nil, err
+	}
+
+	res, err := r.db.Exec(`INSERT INTO idempotency_keys(key, method, path, request_hash) VALUES($1, $2, $3, $4)
+		ON CONFLICT (key, method, path) DO NOTHING`, req.Key, req.Method, req.Path, req.RequestHash)
+	if err != nil {
+		return nil, err
+	}
+	claimed, err := res.RowsAffected()
+	if err != nil {
+		return nil, err
+	}
+	if claimed == 1 {
+		return nil, nil
+	}
+
+	stored := controller.IdempotentRequest{Key: req.Key, Method: req.Method, Path: req.Path}
+	var status sql.NullInt64
+	err = r.db.QueryRow("SELECT request_hash, status, content_type, body, created_at FROM idempotency_keys WHERE key = $1 AND method = $2 AND path = $3",
+		req.Key, req.Method, req.Path).Scan(&stored.RequestHash, &status, &stored.ContentType, &stored.Body, &stored.CreatedAt)
+	// Ключ освободили между вставкой и чтением, клиент может повторить запрос
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, controller.ErrIdempotencyKeyInProgress
+	}
+	if err != nil {
+		return nil, err
+	}
+	stored.Status = int(status.Int64)
+
+	return &stored, nil
+}
+
+func (r idempotencyRepository) Save(req *controller.IdempotentRequest) error {
+	_, err := r.db.Exec("UPDATE idempotency_keys SET status = $1, content_type = $2, body = $3 WHERE key = $4 AND method = $5 AND path = $6",
+		req.Status, req.ContentType, req.Body, req.Key, req.Method, req.Path)
+
+	return err
+}
+
+func (r idempotencyRepository) Release(req *controller.IdempotentRequest) error {
+	_, err := r.db.Exec("DELETE FROM idempotency_keys WHERE key = $1 AND method = $2 AND path = $3 AND status IS NULL",
+		req.Key, req.Method, req.Path)
+
+	return err
+}
+
+func (r idempotencyRepository) Purge(ttl time.Duration) (int, error) {
+	res, err := r.db.Exec("DELETE FROM idempotency_keys WHERE created_at < NOW() - make_interval(secs => $1)", ttl.Seconds())
+	if err != nil {
+		return 0, err
+	}
+	purged, err := res.RowsAffected()
+	if err != nil {
+		return 0, err
+	}
+
+	return int(purged), nil
+}
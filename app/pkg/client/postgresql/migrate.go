@@ -0,0 +1,201 @@
+package postgresql
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+
+	"github.com/golang-migrate/migrate/v4"
+	"github.com/golang-migrate/migrate/v4/database"
+	"github.com/golang-migrate/migrate/v4/database/postgres"
+	"github.com/golang-migrate/migrate/v4/source"
+	"github.com/golang-migrate/migrate/v4/source/iofs"
+)
+
+// ErrSchemaDirty возвращается, если предыдущая миграция прервалась и схему нужно исправить вручную
+var ErrSchemaDirty = errors.New("database schema is dirty, fix it manually and run migrate force")
+
+// Migrator применяет миграции golang-migrate из fs.FS.
+// Версия схемы хранится в schema_migrations, поэтому мигратор работает и с базами, размеченными внешним migrate
+type Migrator struct {
+	m        *migrate.Migrate
+	versions []uint
+}
+
+// NewMigrator создает мигратор с миграциями из корня fsys.
+// Мигратор занимает отдельное соединение из db до вызова Close
+func NewMigrator(ctx context.Context, db *sql.DB, fsys fs.FS) (*Migrator, error) {
+	src, err := iofs.New(fsys, ".")
+	if err != nil {
+		return nil, err
+	}
+	versions, err := sourceVersions(src)
+	if err != nil {
+		return nil, err
+	}
+
+	// Драйвер, созданный через WithInstance, закрывает db вместе с собой, поэтому передаем ему только соединение
+	conn, err := db.Conn(ctx)
+	if err != nil {
+		return nil, err
+	}
+	driver, err := postgres.WithConnection(ctx, conn, &postgres.Config{})
+	if err != nil {
+		conn.Close()
+		return nil, err
+	}
+	m, err := migrate.NewWithInstance("iofs", src, "postgres", driver)
+	if err != nil {
+		driver.Close()
+		return nil, err
+	}
+
+	return &Migrator{m: m, versions: versions}, nil
+}
+
+// sourceVersions возвращает версии миграций по возрастанию.
+// У каждой версии должны быть оба файла, up и down
+func sourceVersions(src source.Driver) ([]uint, error) {
+	var versions []uint
+	version, err := src.First()
+	for err == nil {
+		up, _, upErr := src.ReadUp(version)
+		if upErr != nil {
+			return nil, fmt.Errorf("migration %d has no up file: %w", version, upErr)
+		}
+		up.Close()
+		down, _, downErr := src.ReadDown(version)
+		if downErr != nil {
+			return nil, fmt.Errorf("migration %d has no down file: %w", version, downErr)
+		}
+		down.Close()
+
+		versions = append(versions, version)
+		version, err = src.Next(version)
+	}
+	if !errors.Is(err, os.ErrNotExist) {
+		return nil, err
+	}
+
+	return versions, nil
+}
+
+// Close освобождает соединение мигратора
+func (m *Migrator) Close() error {
+	srcErr, dbErr := m.m.Close()
+	if srcErr != nil {
+		return srcErr
+	}
+
+	return dbErr
+}
+
+// Latest возвращает последнюю известную версию схемы
+func (m *Migrator) Latest() uint {
+	if len(m.versions) == 0 {
+		return 0
+	}
+
+	return m.versions[len(m.versions)-1]
+}
+
+// Version возвращает текущую версию схемы базы, 0 - миграции не применялись
+func (m *Migrator) Version() (version uint, dirty bool, err error) {
+	version, dirty, err = m.m.Version()
+	if errors.Is(err, migrate.ErrNilVersion) {
+		return 0, false, nil
+	}
+
+	return version, dirty, err
+}
+
+// Check проверяет, что схема базы совпадает с последней известной версией и не испорчена
+func (m *Migrator) Check() error {
+	version, dirty, err := m.Version()
+	if err != nil {
+		return err
+	}
+	if dirty {
+		return fmt.Errorf("version %d: %w", version, ErrSchemaDirty)
+	}
+	if version != m.Latest() {
+		return fmt.Errorf("database schema version %d is incompatible, expected %d", version, m.Latest())
+	}
+
+	return nil
+}
+
+// Up применяет все недостающие миграции и возвращает версии схемы до и после
+func (m *Migrator) Up() (from, to uint, err error) {
+	return m.run(m.m.Up)
+}
+
+// Down откатывает steps последних примененных миграций, steps < 0 откатывает все,
+// и возвращает версии схемы до и после
+func (m *Migrator) Down(steps int) (from, to uint, err error) {
+	if steps < 0 {
+		return m.run(m.m.Down)
+	}
+
+	// Если примененных миграций меньше steps, откатываются все
+	return m.run(func() error {
+		err := m.m.Steps(-steps)
+		if errors.As(err, new(migrate.ErrShortLimit)) {
+			return nil
+		}
+		return err
+	})
+}
+
+// Force записывает версию схемы и снимает отметку о прерванной миграции, сами миграции не выполняются
+func (m *Migrator) Force(version uint) error {
+	if version == 0 {
+		return m.m.Force(database.NilVersion)
+	}
+	if !m.known(version) {
+		return fmt.Errorf("unknown migration version %d", version)
+	}
+
+	return m.m.Force(int(version))
+}
+
+// run проверяет, что схема не испорчена и известна мигратору, и выполняет fn.
+// Отсутствие изменений ошибкой не считается
+func (m *Migrator) run(fn func() error) (from, to uint, err error) {
+	from, dirty, err := m.Version()
+	if err != nil {
+		return 0, 0, err
+	}
+	if dirty {
+		return from, from, fmt.Errorf("version %d: %w", from, ErrSchemaDirty)
+	}
+	// База новее приложения: откатывать или накатывать ее этим бинарником нельзя
+	if from != 0 && !m.known(from) {
+		return from, from, fmt.Errorf("database schema version %d is unknown, latest known is %d", from, m.Latest())
+	}
+
+	err = fn()
+	if errors.Is(err, migrate.ErrNoChange) {
+		err = nil
+	}
+	to, _, versionErr := m.Version()
+	if err == nil {
+		err = versionErr
+	}
+
+	return from, to, err
+}
+
+// known проверяет, что версия есть среди миграций
+func (m *Migrator) known(version uint) bool {
+	for _, v := range m.versions {
+		if v == version {
+			return true
+		}
+	}
+
+	return false
+}
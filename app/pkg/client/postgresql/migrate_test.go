@@ -0,0 +1,203 @@
+package postgresql
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"os"
+	"testing"
+	"testing/fstest"
+	"time"
+
+	"lamoda-test/migrations"
+
+	"github.com/golang-migrate/migrate/v4/source/iofs"
+)
+
+func TestSourceVersionsEmbedded(t *testing.T) {
+	src, err := iofs.New(migrations.FS, ".")
+	if err != nil {
+		t.Fatal(err)
+	}
+	versions, err := sourceVersions(src)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(versions) == 0 {
+		t.Fatal("Expected embedded migrations, got none")
+	}
+
+	// Версии идут подряд с 1, иначе golang-migrate и приложение разойдутся в версии схемы
+	for i, v := range versions {
+		if v != uint(i+1) {
+			t.Errorf("Expected migration version %d, got %d", i+1, v)
+		}
+	}
+}
+
+func TestSourceVersionsMissingDown(t *testing.T) {
+	fsys := fstest.MapFS{
+		"000001_init.up.sql":   {Data: []byte("CREATE TABLE a (id INT);")},
+		"000001_init.down.sql": {Data: []byte("DROP TABLE a;")},
+		"000002_more.up.sql":   {Data: []byte("CREATE TABLE b (id INT);")},
+	}
+
+	src, err := iofs.New(fsys, ".")
+	if err != nil {
+		t.Fatal(err)
+	}
+	_, err = sourceVersions(src)
+	if err == nil {
+		t.Error("Expected an error for migration without down file, but got nil")
+	}
+
+	delete(fsys, "000002_more.up.sql")
+	src, err = iofs.New(fsys, ".")
+	if err != nil {
+		t.Fatal(err)
+	}
+	versions, err := sourceVersions(src)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(versions) != 1 || versions[0] != 1 {
+		t.Errorf("Unexpected versions: %v", versions)
+	}
+}
+
+func TestMigratorUpDownUp(t *testing.T) {
+	db := testDB(t)
+	ctx := context.Background()
+
+	m, err := NewMigrator(ctx, db, migrations.FS)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer m.Close()
+
+	from, to, err := m.Up()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if from != 0 || to != m.Latest() {
+		t.Errorf("Expected up from 0 to %d, got %d to %d", m.Latest(), from, to)
+	}
+	if err := m.Check(); err != nil {
+		t.Errorf("Expected schema to be up to date, got %v", err)
+	}
+
+	// Откат одной миграции, затем всех
+	_, to, err = m.Down(1)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if to != m.Latest()-1 {
+		t.Errorf("Expected version %d after one step down, got %d", m.Latest()-1, to)
+	}
+	_, to, err = m.Down(-1)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if to != 0 {
+		t.Errorf("Expected version 0 after full down, got %d", to)
+	}
+
+	// После полного отката схема снова поднимается до последней версии
+	_, to, err = m.Up()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if to != m.Latest() {
+		t.Errorf("Expected version %d after second up, got %d", m.Latest(), to)
+	}
+}
+
+func TestMigratorRefusesDirtyAndUnknownVersion(t *testing.T) {
+	db := testDB(t)
+	ctx := context.Background()
+
+	m, err := NewMigrator(ctx, db, migrations.FS)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer m.Close()
+	if _, _, err := m.Up(); err != nil {
+		t.Fatal(err)
+	}
+
+	// Прерванная миграция: схема помечена как испорченная
+	_, err = db.Exec("UPDATE schema_migrations SET dirty = TRUE")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, _, err := m.Up(); !errors.Is(err, ErrSchemaDirty) {
+		t.Errorf("Expected ErrSchemaDirty on up, got %v", err)
+	}
+	if err := m.Check(); !errors.Is(err, ErrSchemaDirty) {
+		t.Errorf("Expected ErrSchemaDirty on check, got %v", err)
+	}
+	if err := m.Force(m.Latest()); err != nil {
+		t.Fatal(err)
+	}
+
+	// База размечена версией новее приложения
+	_, err = db.Exec("UPDATE schema_migrations SET version = $1", m.Latest()+1)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, _, err := m.Down(1); err == nil {
+		t.Error("Expected an error for unknown schema version on down, but got nil")
+	}
+	if err := m.Check(); err == nil {
+		t.Error("Expected an error for unknown schema version on check, but got nil")
+	}
+	if err := m.Force(m.Latest() + 1); err == nil {
+		t.Error("Expected an error when forcing unknown version, but got nil")
+	}
+}
+
+// testDB подключается к базе из POSTGRES_* при TEST_STORAGE=postgres и возвращает соединение
+// с отдельной пустой схемой, чтобы откат миграций не затронул другие тесты. Без базы тест пропускается
+func testDB(t *testing.T) *sql.DB {
+	t.Helper()
+
+	if os.Getenv("TEST_STORAGE") != "postgres" {
+		t.Skip("postgres tests are enabled with TEST_STORAGE=postgres")
+	}
+
+	host, port := os.Getenv("POSTGRES_HOST"), os.Getenv("POSTGRES_PORT")
+	if host == "" {
+		host = "localhost"
+	}
+	if port == "" {
+		port = "5432"
+	}
+	dsn := fmt.Sprintf("postgres://%s:%s@%s:%s/%s?sslmode=disable",
+		os.Getenv("POSTGRES_USER"), os.Getenv("POSTGRES_PASS"), host, port, os.Getenv("POSTGRES_NAME"))
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+	admin, err := sql.Open("postgres", dsn)
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { admin.Close() })
+	if err := admin.PingContext(ctx); err != nil {
+		t.Skipf("postgres is not available: %v", err)
+	}
+
+	schema := fmt.Sprintf("migrate_test_%d", time.Now().UnixNano())
+	if _, err := admin.Exec("CREATE SCHEMA " + schema); err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { admin.Exec("DROP SCHEMA " + schema + " CASCADE") })
+
+	db, err := sql.Open("postgres", dsn+"&search_path="+schema)
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { db.Close() })
+
+	return db
+}
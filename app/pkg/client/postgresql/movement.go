@@ -0,0 +1,76 @@
+package postgresql
+
+import (
+	"database/sql"
+	"fmt"
+
+	"lamoda-test/api/controller"
+)
+
+type movementRepository struct {
+	q querier
+}
+
+func (r movementRepository) Record(m controller.StockMovement) error {
+	_, err := r.q.Exec(`INSERT INTO stock_movements(product_id, warehouse_id, code, kind, quantity_delta, reserved_delta, reservation_id, transfer_id, receipt_id, adjustment_id)
+		VALUES($1, NULLIF($2, 0), $3, $4, $5, $6, $7, $8, $9, $10)`,
+		m.ProductID, m.WarehouseID, m.Code, m.Kind, m.QuantityDelta, m.ReservedDelta, m.ReservationID, m.TransferID, m.ReceiptID, m.AdjustmentID)
+
+	return err
+}
+
+func (r movementRepository) List(f controller.MovementFilter) (*controller.MovementPage, error) {
+	// Собираем условия фильтрации
+	var c conditions
+	if f.ProductID != 0 {
+		c.add("product_id = $%d", f.ProductID)
+	}
+	if f.WarehouseID != 0 {
+		c.add("warehouse_id = $%d", f.WarehouseID)
+	}
+	if f.Code != "" {
+		c.add("code = $%d", f.Code)
+	}
+	if f.Kind != "" {
+		c.add("kind = $%d", f.Kind)
+	}
+	if !f.From.IsZero() {
+		c.add("created_at >= $%d", f.From)
+	}
+	if !f.To.IsZero() {
+		c.add("created_at < $%d", f.To)
+	}
+
+	page := controller.MovementPage{Limit: f.Limit, Offset: f.Offset, Items: []controller.StockMovement{}}
+	err := r.q.QueryRow("SELECT COUNT(*) FROM stock_movements"+c.where(), c.args...).Scan(&page.Total)
+	if err != nil {
+		return nil, err
+	}
+
+	query := fmt.Sprintf(`SELECT id, product_id, warehouse_id, code, kind, quantity_delta, reserved_delta, reservation_id, transfer_id, receipt_id, adjustment_id, created_at
+		FROM stock_movements%s ORDER BY created_at DESC, id DESC LIMIT %d OFFSET %d`, c.where(), f.Limit, f.Offset)
+	rows, err := r.q.Query(query, c.args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var m controller.StockMovement
+		var warehouseID, reservationID, transferID, receiptID, adjustmentID sql.NullInt64
+		if err := rows.Scan(&m.ID, &m.ProductID, &warehouseID, &m.Code, &m.Kind, &m.QuantityDelta, &m.ReservedDelta, &reservationID, &transferID, &receiptID, &adjustmentID, &m.CreatedAt); err != nil {
+			return nil, err
+		}
+		m.WarehouseID = int(warehouseID.Int64)
+		m.ReservationID = nullableID(reservationID)
+		m.TransferID = nullableID(transferID)
+		m.ReceiptID = nullableID(receiptID)
+		m.AdjustmentID = nullableID(adjustmentID)
+		page.Items = append(page.Items, m)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+
+	return &page, nil
+}
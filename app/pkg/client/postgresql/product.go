@@ -0,0 +1,243 @@
+package postgresql
+
+import (
+	"database/sql"
+	"errors"
+	"fmt"
+	"strings"
+
+	"github.com/lib/pq"
+
+	"lamoda-test/api/controller"
+)
+
+// productColumns колонки продукта в порядке, ожидаемом scanProduct
+const productColumns = "id, name, size, code, quantity, reserved_quantity, warehouse_id"
+
+// productSortColumns колонки для полей сортировки списка продуктов
+var productSortColumns = map[string]string{
+	"id":        "id",
+	"name":      "name",
+	"code":      "code",
+	"size":      "size",
+	"quantity":  "quantity",
+	"reserved":  "reserved_quantity",
+	"available": "quantity - reserved_quantity",
+}
+
+type productRepository struct {
+	q querier
+}
+
+// scanProduct считывает продукт и вычисляет доступный остаток
+func scanProduct(s scanner) (controller.Product, error) {
+	var p controller.Product
+	var name, size sql.NullString
+	var warehouseID sql.NullInt64
+	err := s.Scan(&p.ID, &name, &size, &p.Code, &p.Quantity, &p.ReservedQuantity, &warehouseID)
+	if err != nil {
+		return p, err
+	}
+	p.Name = name.String
+	p.Size = size.String
+	p.WarehouseID = int(warehouseID.Int64)
+	p.Available = p.Quantity - p.ReservedQuantity
+
+	return p, nil
+}
+
+// queryProducts выполняет запрос и считывает все продукты
+func queryProducts(q querier, query string, args ...interface{}) ([]controller.Product, error) {
+	rows, err := q.Query(query, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	products := []controller.Product{}
+	for rows.Next() {
+		p, err := scanProduct(rows)
+		if err != nil {
+			return nil, err
+		}
+		products = append(products, p)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+
+	return products, nil
+}
+
+func (r productRepository) Create(p *controller.Product) error {
+	err := r.q.QueryRow("INSERT INTO products(name, size, code, quantity, warehouse_id) VALUES($1, $2, $3, $4, $5) RETURNING id",
+		p.Name, p.Size, p.Code, p.Quantity, p.WarehouseID).Scan(&p.ID)
+	var pqErr *pq.Error
+	if errors.As(err, &pqErr) {
+		// Нарушения ограничений означают ошибку в запросе, а не сбой базы
+		switch pqErr.Code {
+		case "23505":
+			return controller.ErrProductExists.WithProduct(p.Code)
+		case "23503":
+			return controller.ErrWarehouseNotFound.WithWarehouse(p.WarehouseID)
+		}
+	}
+
+	return err
+}
+
+func (r productRepository) Restock(p *controller.Product) error {
+	return r.q.QueryRow(`INSERT INTO products(name, size, code, quantity, warehouse_id) VALUES($1, $2, $3, $4, $5)
+		ON CONFLICT (warehouse_id, code) DO UPDATE SET quantity = products.quantity + EXCLUDED.quantity
+		RETURNING id`, p.Name, p.Size, p.Code, p.Quantity, p.WarehouseID).Scan(&p.ID)
+}
+
+func (r productRepository) CreateIfMissing(p *controller.Product) error {
+	_, err := r.q.Exec(`INSERT INTO products(name, size, code, quantity, warehouse_id) VALUES($1, $2, $3, $4, $5)
+		ON CONFLICT (warehouse_id, code) DO NOTHING`, p.Name, p.Size, p.Code, p.Quantity, p.WarehouseID)
+
+	return err
+}
+
+func (r productRepository) Get(id int) (*controller.Product, error) {
+	return r.get(id, "")
+}
+
+func (r productRepository) GetForUpdate(id int) (*controller.Product, error) {
+	return r.get(id, " FOR UPDATE")
+}
+
+// get считывает продукт по ID с заданной блокировкой
+func (r productRepository) get(id int, lock string) (*controller.Product, error) {
+	p, err := scanProduct(r.q.QueryRow("SELECT "+productColumns+" FROM products WHERE id = $1"+lock, id))
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, controller.ErrProductNotFound
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	return &p, nil
+}
+
+func (r productRepository) List(f controller.ProductFilter) (*controller.ProductPage, error) {
+	orderBy := "id"
+	if f.Sort != "" {
+		field, direction := f.Sort, "ASC"
+		if strings.HasPrefix(field, "-") {
+			field, direction = field[1:], "DESC"
+		}
+		column, ok := productSortColumns[field]
+		if !ok {
+			return nil, controller.ErrInvalidSort
+		}
+		orderBy = fmt.Sprintf("%s %s, id", column, direction)
+	}
+
+	// Собираем условия фильтрации
+	var c conditions
+	if f.WarehouseID != 0 {
+		c.add("warehouse_id = $%d", f.WarehouseID)
+	}
+	if f.Name != "" {
+		escaped := strings.NewReplacer(`\`, `\\`, "%", `\%`, "_", `\_`).Replace(f.Name)
+		c.add("name ILIKE '%%' || $%d || '%%'", escaped)
+	}
+	if f.Size != "" {
+		c.add("size = $%d", f.Size)
+	}
+	if f.MinQuantity != nil {
+		c.add("quantity >= $%d", *f.MinQuantity)
+	}
+	if f.MaxQuantity != nil {
+		c.add("quantity <= $%d", *f.MaxQuantity)
+	}
+	if f.OutOfStock {
+		c.raw("quantity - reserved_quantity <= 0")
+	}
+
+	page := controller.ProductPage{Limit: f.Limit, Offset: f.Offset}
+	err := r.q.QueryRow("SELECT COUNT(*) FROM products"+c.where(), c.args...).Scan(&page.Total)
+	if err != nil {
+		return nil, err
+	}
+
+	query := fmt.Sprintf("SELECT %s FROM products%s ORDER BY %s LIMIT %d OFFSET %d", productColumns, c.where(), orderBy, f.Limit, f.Offset)
+	page.Items, err = queryProducts(r.q, query, c.args...)
+	if err != nil {
+		return nil, err
+	}
+
+	return &page, nil
+}
+
+func (r productRepository) ListByCode(code string) ([]controller.Product, error) {
+	return queryProducts(r.q, "SELECT "+productColumns+" FROM products WHERE code = $1 ORDER BY warehouse_id", code)
+}
+
+func (r productRepository) LockByCode(code string, warehouseIDs ...int) ([]controller.Product, error) {
+	if len(warehouseIDs) == 0 {
+		return queryProducts(r.q, "SELECT "+productColumns+" FROM products WHERE code = $1 ORDER BY id FOR UPDATE", code)
+	}
+
+	return queryProducts(r.q, "SELECT "+productColumns+" FROM products WHERE code = $1 AND warehouse_id = ANY($2) ORDER BY id FOR UPDATE", code, pq.Array(warehouseIDs))
+}
+
+func (r productRepository) LockByWarehouse(warehouseID int) ([]controller.Product, error) {
+	return queryProducts(r.q, "SELECT "+productColumns+" FROM products WHERE warehouse_id = $1 ORDER BY code, id FOR UPDATE", warehouseID)
+}
+
+func (r productRepository) LockStock(code string, warehouseID int) ([]controller.StockLevel, error) {
+	// Склады блокируются раньше строк продукта, как требует controller.WarehouseRepository
+	_, err := r.q.Exec(`SELECT id FROM warehouse
+		WHERE id IN (SELECT warehouse_id FROM products WHERE code = $1 AND ($2 = 0 OR warehouse_id = $2))
+		ORDER BY id
+		FOR SHARE`, code, warehouseID)
+	if err != nil {
+		return nil, err
+	}
+
+	rows, err := r.q.Query(`SELECT p.id, p.quantity - p.reserved_quantity, w.id, w.name, w.is_available, w.priority, w.latitude, w.longitude
+		FROM products p JOIN warehouse w ON w.id = p.warehouse_id
+		WHERE p.code = $1 AND ($2 = 0 OR p.warehouse_id = $2)
+		ORDER BY p.id
+		FOR UPDATE OF p`, code, warehouseID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var stock []controller.StockLevel
+	for rows.Next() {
+		var s controller.StockLevel
+		w, err := scanWarehouse(rows, &s.ProductID, &s.Available)
+		if err != nil {
+			return nil, err
+		}
+		s.Warehouse = w
+		stock = append(stock, s)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+
+	return stock, nil
+}
+
+func (r productRepository) Update(p *controller.Product) error {
+	_, err := r.q.Exec("UPDATE products SET name = $1, size = $2, quantity = $3 WHERE id = $4", p.Name, p.Size, p.Quantity, p.ID)
+
+	return err
+}
+
+func (r productRepository) AddStock(id, quantityDelta, reservedDelta int) error {
+	_, err := r.q.Exec("UPDATE products SET quantity = quantity + $1, reserved_quantity = reserved_quantity + $2 WHERE id = $3", quantityDelta, reservedDelta, id)
+
+	return err
+}
+
+func (r productRepository) Delete(id int) error {
+	_, err := r.q.Exec("DELETE FROM products WHERE id = $1", id)
+
+	return err
+}
@@ -0,0 +1,62 @@
+package postgresql
+
+import (
+	"database/sql"
+	"errors"
+
+	"lamoda-test/api/controller"
+)
+
+type receiptRepository struct {
+	q querier
+}
+
+func (r receiptRepository) Create(rc *controller.Receipt) error {
+	err := r.q.QueryRow("INSERT INTO receipts(supplier_ref) VALUES($1) RETURNING id, created_at", rc.SupplierRef).Scan(&rc.ID, &rc.CreatedAt)
+	if err != nil {
+		return err
+	}
+	for _, l := range rc.Lines {
+		_, err = r.q.Exec("INSERT INTO receipt_lines(receipt_id, product_id, warehouse_id, code, quantity) VALUES($1, $2, $3, $4, $5)",
+			rc.ID, l.ProductID, l.WarehouseID, l.Code, l.Quantity)
+		if err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+func (r receiptRepository) Get(id int) (*controller.Receipt, error) {
+	var rc controller.Receipt
+	err := r.q.QueryRow("SELECT id, supplier_ref, created_at FROM receipts WHERE id = $1", id).Scan(&rc.ID, &rc.SupplierRef, &rc.CreatedAt)
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, controller.ErrReceiptNotFound
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	rows, err := r.q.Query("SELECT product_id, warehouse_id, code, quantity FROM receipt_lines WHERE receipt_id = $1 ORDER BY id", rc.ID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	rc.Lines = []controller.ReceiptLine{}
+	for rows.Next() {
+		var l controller.ReceiptLine
+		var productID, warehouseID sql.NullInt64
+		if err := rows.Scan(&productID, &warehouseID, &l.Code, &l.Quantity); err != nil {
+			return nil, err
+		}
+		l.ProductID = int(productID.Int64)
+		l.WarehouseID = int(warehouseID.Int64)
+		rc.Lines = append(rc.Lines, l)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+
+	return &rc, nil
+}
@@ -0,0 +1,193 @@
+package postgresql
+
+import (
+	"database/sql"
+	"errors"
+	"time"
+
+	"lamoda-test/api/controller"
+)
+
+type reservationRepository struct {
+	q querier
+}
+
+func (r reservationRepository) Create(res *controller.Reservation) error {
+	err := r.q.QueryRow("INSERT INTO reservations(order_ref, warehouse_id, status, expires_at) VALUES($1, NULLIF($2, 0), $3, $4) RETURNING id, created_at, updated_at",
+		res.OrderRef, res.WarehouseID, res.Status, res.ExpiresAt).Scan(&res.ID, &res.CreatedAt, &res.UpdatedAt)
+	if err != nil {
+		return err
+	}
+	for _, l := range res.Lines {
+		_, err = r.q.Exec("INSERT INTO reservation_lines(reservation_id, product_id, warehouse_id, code, quantity) VALUES($1, $2, $3, $4, $5)",
+			res.ID, l.ProductID, l.WarehouseID, l.Code, l.Quantity)
+		if err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+func (r reservationRepository) Get(id int) (*controller.Reservation, error) {
+	return r.get(id, "")
+}
+
+func (r reservationRepository) GetForUpdate(id int) (*controller.Reservation, error) {
+	return r.get(id, " FOR UPDATE")
+}
+
+// get считывает резервирование по ID с заданной блокировкой вместе со строками
+func (r reservationRepository) get(id int, lock string) (*controller.Reservation, error) {
+	var res controller.Reservation
+	var warehouseID sql.NullInt64
+	err := r.q.QueryRow("SELECT id, order_ref, warehouse_id, status, created_at, updated_at, expires_at FROM reservations WHERE id = $1"+lock, id).
+		Scan(&res.ID, &res.OrderRef, &warehouseID, &res.Status, &res.CreatedAt, &res.UpdatedAt, &res.ExpiresAt)
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, controller.ErrReservationNotFound
+	}
+	if err != nil {
+		return nil, err
+	}
+	res.WarehouseID = int(warehouseID.Int64)
+
+	res.Lines, err = r.lines(res.ID)
+	if err != nil {
+		return nil, err
+	}
+
+	return &res, nil
+}
+
+// lines возвращает строки резервирования
+func (r reservationRepository) lines(reservationID int) ([]controller.ReservationLine, error) {
+	rows, err := r.q.Query("SELECT product_id, warehouse_id, code, quantity FROM reservation_lines WHERE reservation_id = $1 ORDER BY id", reservationID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	lines := []controller.ReservationLine{}
+	for rows.Next() {
+		var l controller.ReservationLine
+		var productID, warehouseID sql.NullInt64
+		if err := rows.Scan(&productID, &warehouseID, &l.Code, &l.Quantity); err != nil {
+			return nil, err
+		}
+		l.ProductID = int(productID.Int64)
+		l.WarehouseID = int(warehouseID.Int64)
+		lines = append(lines, l)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+
+	return lines, nil
+}
+
+func (r reservationRepository) SetStatus(res *controller.Reservation, status string) error {
+	err := r.q.QueryRow("UPDATE reservations SET status = $1, updated_at = NOW() WHERE id = $2 RETURNING updated_at", status, res.ID).Scan(&res.UpdatedAt)
+	if err != nil {
+		return err
+	}
+	res.Status = status
+
+	return nil
+}
+
+func (r reservationRepository) ListExpired(now time.Time) ([]int, error) {
+	rows, err := r.q.Query("SELECT id FROM reservations WHERE status = $1 AND expires_at <= $2 ORDER BY expires_at", controller.ReservationActive, now)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var ids []int
+	for rows.Next() {
+		var id int
+		if err := rows.Scan(&id); err != nil {
+			return nil, err
+		}
+		ids = append(ids, id)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+
+	return ids, nil
+}
+
+func (r reservationRepository) ListHolding(productID int) ([]controller.Reservation, error) {
+	rows, err := r.q.Query(`SELECT id FROM reservations
+		WHERE status = $1 AND id IN (SELECT reservation_id FROM reservation_lines WHERE product_id = $2)
+		ORDER BY created_at DESC, id DESC FOR UPDATE`, controller.ReservationActive, productID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var ids []int
+	for rows.Next() {
+		var id int
+		if err := rows.Scan(&id); err != nil {
+			return nil, err
+		}
+		ids = append(ids, id)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+
+	// Резервирования уже заблокированы, строки читаем без блокировки
+	holding := make([]controller.Reservation, 0, len(ids))
+	for _, id := range ids {
+		res, err := r.get(id, "")
+		if err != nil {
+			return nil, err
+		}
+		holding = append(holding, *res)
+	}
+
+	return holding, nil
+}
+
+func (r reservationRepository) ReduceLines(reservationID, productID, quantity int) error {
+	rows, err := r.q.Query("SELECT id, quantity FROM reservation_lines WHERE reservation_id = $1 AND product_id = $2 ORDER BY id", reservationID, productID)
+	if err != nil {
+		return err
+	}
+	defer rows.Close()
+
+	type line struct{ id, quantity int }
+	var lines []line
+	for rows.Next() {
+		var l line
+		if err := rows.Scan(&l.id, &l.quantity); err != nil {
+			return err
+		}
+		lines = append(lines, l)
+	}
+	if err := rows.Err(); err != nil {
+		return err
+	}
+
+	for _, l := range lines {
+		if quantity == 0 {
+			break
+		}
+		// Строка без единиц удаляется, количество в строке должно быть положительным
+		if l.quantity <= quantity {
+			if _, err := r.q.Exec("DELETE FROM reservation_lines WHERE id = $1", l.id); err != nil {
+				return err
+			}
+			quantity -= l.quantity
+			continue
+		}
+		if _, err := r.q.Exec("UPDATE reservation_lines SET quantity = quantity - $1 WHERE id = $2", quantity, l.id); err != nil {
+			return err
+		}
+		quantity = 0
+	}
+
+	return nil
+}
@@ -0,0 +1,134 @@
+package postgresql
+
+import (
+	"database/sql"
+	"errors"
+	"fmt"
+	"strings"
+
+	"lamoda-test/api/controller"
+)
+
+// querier общий интерфейс *sql.DB и *sql.Tx
+type querier interface {
+	Exec(query string, args ...interface{}) (sql.Result, error)
+	Query(query string, args ...interface{}) (*sql.Rows, error)
+	QueryRow(query string, args ...interface{}) *sql.Row
+}
+
+// scanner общий интерфейс *sql.Row и *sql.Rows
+type scanner interface {
+	Scan(dest ...interface{}) error
+}
+
+var _ controller.Store = (*Store)(nil)
+
+// Store хранилище данных склада в PostgreSQL
+type Store struct {
+	repositories
+	db *sql.DB
+}
+
+// NewStore создает хранилище поверх подключения к базе
+func NewStore(db *sql.DB) *Store {
+	return &Store{repositories: repositories{q: db}, db: db}
+}
+
+// InTx выполняет fn в транзакции базы
+func (s *Store) InTx(fn func(tx controller.Repositories) error) error {
+	tx, err := s.db.Begin()
+	if err != nil {
+		return err
+	}
+	// Откатывает транзакцию при панике в fn, после Commit ничего не делает
+	defer tx.Rollback()
+
+	err = fn(repositories{q: tx})
+	if err != nil {
+		if rollbackErr := tx.Rollback(); rollbackErr != nil {
+			return errors.Join(err, fmt.Errorf("rollback: %w", rollbackErr))
+		}
+		return err
+	}
+
+	err = tx.Commit()
+	if err != nil {
+		return err
+	}
+
+	return nil
+}
+
+// Idempotency возвращает хранилище ключей идемпотентности
+func (s *Store) Idempotency() controller.IdempotencyRepository {
+	return idempotencyRepository{db: s.db}
+}
+
+// repositories хранилища поверх подключения или транзакции
+type repositories struct {
+	q querier
+}
+
+func (r repositories) Warehouses() controller.WarehouseRepository {
+	return warehouseRepository{q: r.q}
+}
+
+func (r repositories) Products() controller.ProductRepository {
+	return productRepository{q: r.q}
+}
+
+func (r repositories) Reservations() controller.ReservationRepository {
+	return reservationRepository{q: r.q}
+}
+
+func (r repositories) Movements() controller.MovementRepository {
+	return movementRepository{q: r.q}
+}
+
+func (r repositories) Transfers() controller.TransferRepository {
+	return transferRepository{q: r.q}
+}
+
+func (r repositories) Receipts() controller.ReceiptRepository {
+	return receiptRepository{q: r.q}
+}
+
+func (r repositories) Adjustments() controller.AdjustmentRepository {
+	return adjustmentRepository{q: r.q}
+}
+
+// conditions собирает условия WHERE с нумерованными параметрами
+type conditions struct {
+	parts []string
+	args  []interface{}
+}
+
+// add добавляет условие, %d в condition заменяется номером параметра arg
+func (c *conditions) add(condition string, arg interface{}) {
+	c.args = append(c.args, arg)
+	c.parts = append(c.parts, fmt.Sprintf(condition, len(c.args)))
+}
+
+// raw добавляет условие без параметров
+func (c *conditions) raw(condition string) {
+	c.parts = append(c.parts, condition)
+}
+
+// where возвращает секцию WHERE или пустую строку
+func (c *conditions) where() string {
+	if len(c.parts) == 0 {
+		return ""
+	}
+
+	return " WHERE " + strings.Join(c.parts, " AND ")
+}
+
+// nullableID преобразует NULL-ссылку в nil
+func nullableID(id sql.NullInt64) *int {
+	if !id.Valid {
+		return nil
+	}
+	v := int(id.Int64)
+
+	return &v
+}
@@ -0,0 +1,103 @@
+package postgresql
+
+import (
+	"context"
+	"database/sql"
+	"testing"
+
+	"lamoda-test/api/controller"
+	"lamoda-test/migrations"
+)
+
+// migratedDB возвращает соединение с отдельной схемой, в которой применены все миграции
+func migratedDB(t *testing.T) *sql.DB {
+	t.Helper()
+
+	db := testDB(t)
+	m, err := NewMigrator(context.Background(), db, migrations.FS)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer m.Close()
+	if _, _, err := m.Up(); err != nil {
+		t.Fatal(err)
+	}
+
+	return db
+}
+
+func TestInTxRollsBackOnPanic(t *testing.T) {
+	db := migratedDB(t)
+
+	// Паника в fn откатывает транзакцию и возвращает соединение в пул
+	store := NewStore(db)
+	func() {
+		defer func() {
+			if recover() == nil {
+				t.Error("Expected panic to propagate from InTx")
+			}
+		}()
+		store.InTx(func(tx controller.Repositories) error {
+			if err := tx.Warehouses().Create(&controller.Warehouse{Name: "north"}); err != nil {
+				return err
+			}
+			panic("handler failed")
+		})
+	}()
+
+	if inUse := db.Stats().InUse; inUse != 0 {
+		t.Errorf("Expected no connections in use, got %d", inUse)
+	}
+	warehouses, err := store.Warehouses().List()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(warehouses) != 0 {
+		t.Errorf("Expected warehouse to be rolled back, got %+v", warehouses)
+	}
+}
+
+func TestWarehouseWithNullColumns(t *testing.T) {
+	db := migratedDB(t)
+	store := NewStore(db)
+
+	// Склад из исходной схемы без названия и доступности
+	var id int
+	err := db.QueryRow("INSERT INTO warehouse(name, is_available) VALUES(NULL, NULL) RETURNING id").Scan(&id)
+	if err != nil {
+		t.Fatal(err)
+	}
+	_, err = db.Exec("INSERT INTO products(name, size, code, quantity, warehouse_id) VALUES('shirt', 'M', 'SHIRT', 1, $1)", id)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	w, err := store.Warehouses().Get(id)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if w.Name != "" || w.IsAvailable {
+		t.Errorf("Expected unnamed unavailable warehouse, got %+v", w)
+	}
+	warehouses, err := store.Warehouses().List()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(warehouses) != 1 {
+		t.Errorf("Expected 1 warehouse, got %+v", warehouses)
+	}
+
+	err = store.InTx(func(tx controller.Repositories) error {
+		stock, err := tx.Products().LockStock("SHIRT", 0)
+		if err != nil {
+			return err
+		}
+		if len(stock) != 1 || stock[0].Warehouse.ID != id || stock[0].Warehouse.IsAvailable {
+			t.Errorf("Expected stock on unavailable warehouse %d, got %+v", id, stock)
+		}
+		return nil
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+}
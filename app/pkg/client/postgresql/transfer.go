@@ -0,0 +1,12 @@
+package postgresql
+
+import "lamoda-test/api/controller"
+
+type transferRepository struct {
+	q querier
+}
+
+func (r transferRepository) Create(t *controller.Transfer) error {
+	return r.q.QueryRow("INSERT INTO transfers(code, quantity, from_warehouse_id, to_warehouse_id) VALUES($1, $2, $3, $4) RETURNING id, created_at",
+		t.Code, t.Quantity, t.FromWarehouseID, t.ToWarehouseID).Scan(&t.ID, &t.CreatedAt)
+}
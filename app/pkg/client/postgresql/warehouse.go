@@ -0,0 +1,96 @@
+package postgresql
+
+import (
+	"database/sql"
+	"errors"
+
+	"lamoda-test/api/controller"
+)
+
+// warehouseColumns колонки склада в порядке, ожидаемом scanWarehouse
+const warehouseColumns = "id, name, is_available, priority, latitude, longitude"
+
+type warehouseRepository struct {
+	q querier
+}
+
+// scanWarehouse считывает склад, dest - колонки запроса перед колонками склада.
+// В строках из исходной схемы название и доступность могут быть NULL, такой склад считается недоступным
+func scanWarehouse(s scanner, dest ...interface{}) (controller.Warehouse, error) {
+	var w controller.Warehouse
+	var name sql.NullString
+	var isAvailable sql.NullBool
+	err := s.Scan(append(dest, &w.ID, &name, &isAvailable, &w.Priority, &w.Latitude, &w.Longitude)...)
+	if err != nil {
+		return w, err
+	}
+	w.Name = name.String
+	w.IsAvailable = isAvailable.Bool
+
+	return w, nil
+}
+
+func (r warehouseRepository) Create(w *controller.Warehouse) error {
+	return r.q.QueryRow("INSERT INTO warehouse(name, is_available, priority, latitude, longitude) VALUES($1, $2, $3, $4, $5) RETURNING id",
+		w.Name, w.IsAvailable, w.Priority, w.Latitude, w.Longitude).Scan(&w.ID)
+}
+
+func (r warehouseRepository) List() ([]controller.Warehouse, error) {
+	rows, err := r.q.Query("SELECT " + warehouseColumns + " FROM warehouse ORDER BY id")
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	warehouses := []controller.Warehouse{}
+	for rows.Next() {
+		w, err := scanWarehouse(rows)
+		if err != nil {
+			return nil, err
+		}
+		warehouses = append(warehouses, w)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+
+	return warehouses, nil
+}
+
+func (r warehouseRepository) Get(id int) (*controller.Warehouse, error) {
+	return r.get(id, "")
+}
+
+func (r warehouseRepository) GetForShare(id int) (*controller.Warehouse, error) {
+	return r.get(id, " FOR SHARE")
+}
+
+func (r warehouseRepository) GetForUpdate(id int) (*controller.Warehouse, error) {
+	return r.get(id, " FOR UPDATE")
+}
+
+// get считывает склад по ID с заданной блокировкой
+func (r warehouseRepository) get(id int, lock string) (*controller.Warehouse, error) {
+	w, err := scanWarehouse(r.q.QueryRow("SELECT "+warehouseColumns+" FROM warehouse WHERE id = $1"+lock, id))
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, controller.ErrWarehouseNotFound.WithWarehouse(id)
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	return &w, nil
+}
+
+func (r warehouseRepository) Update(w *controller.Warehouse) error {
+	_, err := r.q.Exec("UPDATE warehouse SET name = $2, is_available = $3, priority = $4, latitude = $5, longitude = $6 WHERE id = $1",
+		w.ID, w.Name, w.IsAvailable, w.Priority, w.Latitude, w.Longitude)
+
+	return err
+}
+
+func (r warehouseRepository) Delete(id int) error {
+	_, err := r.q.Exec("DELETE FROM warehouse WHERE id = $1", id)
+
+	return err
+}
@@ -0,0 +1,92 @@
+// Package metrics содержит метрики Prometheus приложения: HTTP-запросы, пул соединений с базой и операции с остатками
+package metrics
+
+import (
+	"database/sql"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/collectors"
+)
+
+const namespace = "lamoda"
+
+// Registry реестр метрик, который отдает /metrics
+var Registry = prometheus.NewRegistry()
+
+var (
+	// HTTPRequests количество обработанных запросов по маршруту, методу и статусу
+	HTTPRequests = prometheus.NewCounterVec(prometheus.CounterOpts{
+		Namespace: namespace,
+		Subsystem: "http",
+		Name:      "requests_total",
+		Help:      "Number of handled HTTP requests by route, method and status.",
+	}, []string{"route", "method", "status"})
+
+	// HTTPRequestDuration время обработки запросов по маршруту и методу
+	HTTPRequestDuration = prometheus.NewHistogramVec(prometheus.HistogramOpts{
+		Namespace: namespace,
+		Subsystem: "http",
+		Name:      "request_duration_seconds",
+		Help:      "HTTP request latency by route and method.",
+		Buckets:   prometheus.DefBuckets,
+	}, []string{"route", "method"})
+
+	// ReservationsCreated количество созданных резервирований
+	ReservationsCreated = prometheus.NewCounter(prometheus.CounterOpts{
+		Namespace: namespace,
+		Subsystem: "inventory",
+		Name:      "reservations_created_total",
+		Help:      "Number of created reservations.",
+	})
+
+	// ReservationsFailed количество неудачных резервирований по коду ошибки
+	ReservationsFailed = prometheus.NewCounterVec(prometheus.CounterOpts{
+		Namespace: namespace,
+		Subsystem: "inventory",
+		Name:      "reservations_failed_total",
+		Help:      "Number of failed reservations by error code.",
+	}, []string{"reason"})
+
+	// UnitsReserved количество зарезервированных единиц
+	UnitsReserved = prometheus.NewCounter(prometheus.CounterOpts{
+		Namespace: namespace,
+		Subsystem: "inventory",
+		Name:      "units_reserved_total",
+		Help:      "Number of reserved product units.",
+	})
+
+	// UnitsReleased количество единиц, возвращенных из резерва в доступный остаток, по виду движения
+	UnitsReleased = prometheus.NewCounterVec(prometheus.CounterOpts{
+		Namespace: namespace,
+		Subsystem: "inventory",
+		Name:      "units_released_total",
+		Help:      "Number of product units returned from reserve to available stock by movement kind.",
+	}, []string{"kind"})
+
+	// OutOfStock количество случаев, когда склад не смог покрыть строку резервирования
+	OutOfStock = prometheus.NewCounterVec(prometheus.CounterOpts{
+		Namespace: namespace,
+		Subsystem: "inventory",
+		Name:      "out_of_stock_total",
+		Help:      "Number of reservation lines a warehouse could not cover by warehouse.",
+	}, []string{"warehouse_id"})
+)
+
+func init() {
+	Registry.MustRegister(
+		collectors.NewGoCollector(),
+		collectors.NewProcessCollector(collectors.ProcessCollectorOpts{}),
+		HTTPRequests,
+		HTTPRequestDuration,
+		ReservationsCreated,
+		ReservationsFailed,
+		UnitsReserved,
+		UnitsReleased,
+		OutOfStock,
+	)
+}
+
+// RegisterDB добавляет метрики пула соединений с базой dbName
+func RegisterDB(db *sql.DB, dbName string) error {
+	return Registry.Register(collectors.NewDBStatsCollector(db, dbName))
+}
@@ -25,3 +25,8 @@ func RandomInt(length int) int {
 	}
 	return rand.Intn(int(max))
 }
+
+func RandomSize() string {
+	sizes := []string{"XS", "S", "M", "L", "XL", "42", "44-46"}
+	return sizes[rand.Intn(len(sizes))]
+}
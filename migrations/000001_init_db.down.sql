@@ -1,3 +0,0 @@
-DROP TABLE IF EXISTS warehouses CASCADE;
-
-DROP TABLE IF EXISTS products CASCADE;
\ No newline at end of file
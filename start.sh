@@ -1,6 +1,5 @@
 #!/bin/sh
 set -e
-echo "run db migration"
-/app/migrate -path /app/migration -database "$DB_SOURCE" -verbose up
+# Миграции встроены в бинарник и применяются при старте приложения
 echo "start the app"
 exec "$@"
\ No newline at end of file